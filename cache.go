@@ -0,0 +1,241 @@
+package gen
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// cacheKey db setting key of query result cache
+	cacheKey = "gen:cache"
+	// cacheTTLKey db setting key of cache ttl, query results are cached only if it is set
+	cacheTTLKey = "gen:cache_ttl"
+	// cacheKeyPrefix prefix of keys in cache
+	cacheKeyPrefix = "gen:cache:"
+)
+
+// Cache storage of query results, eg: redis, values are opaque bytes, ttl <= 0 means never expire
+type Cache interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// WithCache return db whose queries opted in with Cache(ttl) are cached in cache,
+// No one should use it directly in project, use query.Use(gen.WithCache(db, cache)) instead
+func WithCache(db *gorm.DB, cache Cache) *gorm.DB {
+	return db.Set(cacheKey, cache).Session(new(gorm.Session))
+}
+
+// Cache cache results of First, Take, Find and Count in ttl, entries of model's table are invalidated
+// when Create, Save, Update*, Delete, ForceDelete or Restore run on the model
+func (d *DO) Cache(ttl time.Duration) Dao {
+	if _, ok := d.db.Get(cacheKey); !ok {
+		return d.withError(ErrNoCache)
+	}
+	return d.getInstance(d.db.Session(new(gorm.Session)).Set(cacheTTLKey, ttl).Session(new(gorm.Session)))
+}
+
+// finisher gorm finisher method, eg: (*gorm.DB).First
+type finisher func(db *gorm.DB, dest interface{}, conds ...interface{}) *gorm.DB
+
+// cacheQuery return query of finisher on read db, results are loaded from cache if the query is cached,
+// queries in transaction, in dry run mode or locking rows are never cached
+func (d *DO) cacheQuery(query finisher) func(dest interface{}, conds ...interface{}) *gorm.DB {
+	db := d.readDB()
+	cache, _ := d.db.Get(cacheKey)
+	ttl, cached := d.db.Get(cacheTTLKey)
+	_, locking := d.locking()
+	if _, inTx := d.db.Statement.ConnPool.(gorm.TxCommitter); !cached || cache == nil || inTx || locking || d.db.DryRun || d.db.Error != nil {
+		return func(dest interface{}, conds ...interface{}) *gorm.DB { return query(db, dest, conds...) }
+	}
+
+	c := cache.(Cache)
+	return func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if !cacheable(reflect.TypeOf(dest)) {
+			return query(db, dest, conds...)
+		}
+
+		// render sql in dry run mode as key of results
+		stmt := query(d.db.Session(&gorm.Session{DryRun: true}), dest, conds...).Statement
+		if stmt.Error != nil {
+			return query(db, dest, conds...)
+		}
+		key, err := d.cacheEntryKey(c, fmt.Sprintf("%T:%s", dest, stmt.Explain(stmt.SQL.String(), stmt.Vars...)))
+		if err != nil {
+			d.logCacheError(err)
+			return query(db, dest, conds...)
+		}
+
+		if data, ok, err := c.Get(d.stmtContext(), key); err != nil {
+			d.logCacheError(err)
+		} else if ok && decodeCache(data, dest) == nil {
+			return d.db
+		}
+
+		tx := query(db, dest, conds...)
+		if tx.Error == nil {
+			if data, err := json.Marshal(dest); err != nil {
+				d.logCacheError(err)
+			} else if err := c.Set(d.stmtContext(), key, data, ttl.(time.Duration)); err != nil {
+				d.logCacheError(err)
+			}
+		}
+		return tx
+	}
+}
+
+// cacheableTypes results of types are cacheable or not
+var cacheableTypes sync.Map
+
+// cacheable check if results of type can be cached, results are encoded in json which keeps zero values
+// referenced by pointers, types having fields ignored by json (`json:"-"`) are not cached as they are lost
+func cacheable(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	if ok, loaded := cacheableTypes.Load(t); loaded {
+		return ok.(bool)
+	}
+
+	ok := true
+	for i := 0; i < t.NumField() && ok; i++ {
+		f := t.Field(i)
+		if f.Tag.Get("json") == "-" {
+			ok = false
+		} else if f.Anonymous {
+			ok = cacheable(f.Type)
+		}
+	}
+	cacheableTypes.Store(t, ok)
+	return ok
+}
+
+// decodeCache decode cached results into dest, empty slice is restored as empty instead of nil like Find
+func decodeCache(data []byte, dest interface{}) error {
+	if err := json.Unmarshal(data, dest); err != nil {
+		return err
+	}
+	if rv := reflect.Indirect(reflect.ValueOf(dest)); rv.Kind() == reflect.Slice && rv.IsNil() {
+		rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+	}
+	return nil
+}
+
+// cacheEntryKey return key of entry in current generation of model's table, a new generation is started
+// after the table is invalidated, so that entries of old generation are never read again and expire in ttl
+func (d *DO) cacheEntryKey(c Cache, query string) (string, error) {
+	tableKey := cacheKeyPrefix + d.TableName()
+	generation, ok, err := c.Get(d.stmtContext(), tableKey)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		generation = []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+		if err := c.Set(d.stmtContext(), tableKey, generation, 0); err != nil {
+			return "", err
+		}
+	}
+	sum := sha1.Sum([]byte(query))
+	return tableKey + ":" + string(generation) + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// invalidateCache invalidate cached results of model's table, it is deferred by write finishers,
+// writes in transaction invalidate before commit, so keep ttl short if stale reads matter
+func (d *DO) invalidateCache() {
+	cache, ok := d.db.Get(cacheKey)
+	if !ok || cache == nil {
+		return
+	}
+	if err := cache.(Cache).Delete(d.stmtContext(), cacheKeyPrefix+d.TableName()); err != nil {
+		d.logCacheError(err)
+	}
+}
+
+// stmtContext return context of statement
+func (d *DO) stmtContext() context.Context {
+	if ctx := d.db.Statement.Context; ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// logCacheError log cache error, which is not returned because the query falls back to database
+func (d *DO) logCacheError(err error) {
+	d.db.Logger.Error(d.stmtContext(), "gen cache: %v", err)
+}
+
+// memoryCache in-memory Cache
+type memoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]memoryCacheEntry
+	lastSweep time.Time
+}
+
+type memoryCacheEntry struct {
+	value    []byte
+	expireAt time.Time
+}
+
+// NewMemoryCache return in-memory Cache, which is local to the process
+func NewMemoryCache() Cache {
+	return &memoryCache{entries: make(map[string]memoryCacheEntry), lastSweep: time.Now()}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry := memoryCacheEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expireAt = now.Add(ttl)
+	}
+	c.entries[key] = entry
+
+	// sweep expired entries, entries of old generations are never read again
+	if now.Sub(c.lastSweep) > time.Minute {
+		for k, e := range c.entries {
+			if e.expired(now) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	return nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+func (e memoryCacheEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && now.After(e.expireAt)
+}
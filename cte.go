@@ -0,0 +1,145 @@
+package gen
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CTE common table expression, which can be used as table in Join and Table, or queried directly
+// as SELECT * FROM the CTE, columns of it are referred by field.NewXxx(name, column)
+type CTE struct {
+	*DO
+
+	name      string
+	recursive bool
+	expr      clause.Expr // (query) or (anchor UNION ALL recursive)
+}
+
+// With define common table expression by query, eg:
+//
+//	adults := gen.With("adults", u.Select(u.ID, u.Name).Where(u.Age.Gte(18)))
+//	o.Join(adults, field.NewUint("adults", "id").EqCol(o.UserID)).Find()
+//
+// the above usage is equivalent to SQL statement:
+//
+//	WITH `adults` AS (SELECT `id`,`name` FROM `users` WHERE `age` >= 18) SELECT `orders`.* FROM `orders` INNER JOIN `adults` ON `adults`.`id` = `orders`.`user_id`
+func With(name string, query subQuery) *CTE {
+	return newCTE(name, false, clause.Expr{SQL: "(?)", Vars: []interface{}{query.underlyingDB()}}, query.underlyingDO())
+}
+
+// WithRecursive define recursive common table expression, recursive query refers to the CTE by TableRef(name), eg:
+//
+//	tree := gen.WithRecursive("tree",
+//		c.Where(c.ID.Eq(1)),
+//		c.Select(c.ALL).Join(gen.TableRef("tree"), field.NewUint("tree", "id").EqCol(c.ParentID)),
+//	)
+//	tree.Find() // category 1 and all its descendants
+//
+// the above usage is equivalent to SQL statement:
+//
+//	WITH RECURSIVE `tree` AS (SELECT * FROM `categories` WHERE `id` = 1 UNION ALL SELECT `categories`.* FROM `categories` INNER JOIN `tree` ON `tree`.`id` = `categories`.`parent_id`) SELECT * FROM `tree`
+func WithRecursive(name string, anchor, recursive subQuery) *CTE {
+	expr := clause.Expr{SQL: "(? UNION ALL ?)", Vars: []interface{}{anchor.underlyingDB(), recursive.underlyingDB()}}
+	return newCTE(name, true, expr, anchor.underlyingDO())
+}
+
+func newCTE(name string, recursive bool, expr clause.Expr, query *DO) *CTE {
+	cte := &CTE{name: name, recursive: recursive, expr: expr}
+	// query the CTE directly in model of query, unscoped as the query is already filtered
+	// and columns of model (eg: soft delete column) may be not selected
+	db := query.db.Session(&gorm.Session{NewDB: true}).Unscoped().Table(name).Clauses(withClause{ctes: []*CTE{cte}})
+	cte.DO = query.getInstance(db.Session(new(gorm.Session)))
+	return cte
+}
+
+// TableName return name of CTE
+func (c *CTE) TableName() string { return c.name }
+
+// With prefix statement with common table expressions, CTEs used in Join and Table are prefixed automatically
+func (d *DO) With(ctes ...*CTE) Dao {
+	if len(ctes) == 0 {
+		return d
+	}
+	return d.getInstance(d.db.Clauses(withClause{ctes: ctes}))
+}
+
+// TableRef table referred by name, eg: CTE referred by recursive query of itself
+type TableRef string
+
+// TableName return name of table
+func (t TableRef) TableName() string { return string(t) }
+
+// withClause WITH clause of CTEs, it is built before SELECT clause so that it is kept in subqueries
+type withClause struct {
+	ctes   []*CTE
+	before clause.Expression // expression was before SELECT, eg: hints.CommentBefore
+}
+
+// ModifyStatement merge CTEs into WITH clause before SELECT clause
+func (w withClause) ModifyStatement(stmt *gorm.Statement) {
+	c := stmt.Clauses["SELECT"]
+	var merged withClause
+	switch before := c.BeforeExpression.(type) {
+	case nil:
+	case withClause:
+		merged = before
+	default:
+		merged.before = before
+	}
+	c.BeforeExpression = merged.merge(w.ctes)
+	stmt.Clauses["SELECT"] = c
+}
+
+func (w withClause) merge(ctes []*CTE) withClause {
+	merged := withClause{ctes: append(make([]*CTE, 0, len(w.ctes)+len(ctes)), w.ctes...), before: w.before}
+	for _, cte := range ctes {
+		if !merged.has(cte.name) {
+			merged.ctes = append(merged.ctes, cte)
+		}
+	}
+	return merged
+}
+
+func (w withClause) has(name string) bool {
+	for _, cte := range w.ctes {
+		if cte.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Build build WITH clause
+func (w withClause) Build(builder clause.Builder) {
+	if w.before != nil {
+		w.before.Build(builder)
+		builder.WriteByte(' ')
+	}
+	builder.WriteString("WITH ")
+	for _, cte := range w.ctes {
+		if cte.recursive {
+			builder.WriteString("RECURSIVE ")
+			break
+		}
+	}
+	for i, cte := range w.ctes {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteQuoted(cte.name)
+		builder.WriteString(" AS ")
+		cte.expr.Build(builder)
+	}
+}
+
+// keepBeforeSelect keep expression before SELECT clause (eg: WITH clause) of db after columns are selected,
+// which is dropped by gorm when columns are selected by name
+func keepBeforeSelect(db *gorm.DB, selects func(db *gorm.DB) *gorm.DB) *gorm.DB {
+	before := db.Statement.Clauses["SELECT"].BeforeExpression
+	tx := selects(db)
+	if c := tx.Statement.Clauses["SELECT"]; before != nil && c.BeforeExpression == nil {
+		c.BeforeExpression = before
+		tx.Statement.Clauses["SELECT"] = c
+	}
+	return tx
+}
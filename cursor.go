@@ -0,0 +1,305 @@
+package gen
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/field"
+)
+
+// CursorPage cursors of pages around the current page of keyset pagination, empty if there is no such page
+type CursorPage struct {
+	Next string // cursor of next page
+	Prev string // cursor of previous page
+}
+
+// rowComparisonDialects dialects support row comparison, eg: (a, b) > (?, ?)
+var rowComparisonDialects = map[string]bool{"mysql": true, "postgres": true, "sqlite": true}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// cursorKey sort key of keyset pagination
+type cursorKey struct {
+	column   clause.Column
+	desc     bool
+	nullable bool
+	field    *schema.Field
+}
+
+// reverse return key in reversed order, NULLs are reversed as well
+func (k cursorKey) reverse() cursorKey {
+	k.desc = !k.desc
+	return k
+}
+
+// nullsLast NULLs are placed after values in ascending order, and before values in descending order
+func (k cursorKey) nullsLast() bool { return !k.desc }
+
+func (k cursorKey) orderBy() []clause.Expression {
+	direction := ""
+	if k.desc {
+		direction = " DESC"
+	}
+	exprs := make([]clause.Expression, 0, 2)
+	if k.nullable {
+		// (col IS NULL) sort NULLs explicitly, default NULLs order differs in dialects
+		exprs = append(exprs, clause.Expr{SQL: "? IS NULL" + direction, Vars: []interface{}{k.column}})
+	}
+	return append(exprs, clause.Expr{SQL: "?" + direction, Vars: []interface{}{k.column}})
+}
+
+// after condition matches rows strictly after value, nil if no row matches
+func (k cursorKey) after(value interface{}) clause.Expression {
+	op := " > ?"
+	if k.desc {
+		op = " < ?"
+	}
+	switch {
+	case !k.nullable:
+		return clause.Expr{SQL: "?" + op, Vars: []interface{}{k.column, value}}
+	case isNullValue(value) && k.nullsLast():
+		return nil
+	case isNullValue(value):
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{k.column}}
+	case k.nullsLast():
+		return clause.Or(clause.Expr{SQL: "?" + op, Vars: []interface{}{k.column, value}}, clause.Expr{SQL: "? IS NULL", Vars: []interface{}{k.column}})
+	default:
+		return clause.Expr{SQL: "?" + op, Vars: []interface{}{k.column, value}}
+	}
+}
+
+// equal condition matches rows equal to value
+func (k cursorKey) equal(value interface{}) clause.Expression {
+	if k.nullable && isNullValue(value) {
+		return clause.Expr{SQL: "? IS NULL", Vars: []interface{}{k.column}}
+	}
+	return clause.Expr{SQL: "? = ?", Vars: []interface{}{k.column, value}}
+}
+
+// isNullValue check if value is NULL in database
+func isNullValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return true
+		}
+		v, err := valuer.Value()
+		return err == nil && v == nil
+	}
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// cursorKeys parse sort keys from order columns, columns must be fields of model
+func (d *DO) cursorKeys(columns []field.Expr) ([]cursorKey, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("cursor pagination: no order column")
+	}
+	if d.schema == nil {
+		return nil, fmt.Errorf("cursor pagination: model is required")
+	}
+
+	keys := make([]cursorKey, len(columns))
+	for i, column := range columns {
+		var key cursorKey
+		switch e := column.RawExpr().(type) {
+		case clause.Column:
+			key.column = e
+		case clause.Expr:
+			if e.SQL != "? DESC" || len(e.Vars) != 1 {
+				return nil, fmt.Errorf("cursor pagination: unsupported order column %s", column.Build(d.db.Statement))
+			}
+			col, ok := e.Vars[0].(clause.Column)
+			if !ok {
+				return nil, fmt.Errorf("cursor pagination: unsupported order column %s", column.Build(d.db.Statement))
+			}
+			key.column, key.desc = col, true
+		default:
+			return nil, fmt.Errorf("cursor pagination: unsupported order column %s", column.Build(d.db.Statement))
+		}
+
+		key.field = d.schema.LookUpField(key.column.Name)
+		if key.field == nil {
+			return nil, fmt.Errorf("cursor pagination: order column %s is not field of model", key.column.Name)
+		}
+		key.nullable = key.field.FieldType.Kind() == reflect.Ptr || reflect.PtrTo(key.field.FieldType).Implements(scannerType)
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// cursor position of keyset pagination
+type cursor struct {
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+// encodeCursor encode sort key values of record as cursor
+func encodeCursor(keys []cursorKey, record reflect.Value, backward bool) (string, error) {
+	c := cursor{Values: make([]json.RawMessage, len(keys)), Backward: backward}
+	for i, key := range keys {
+		value, _ := key.field.ValueOf(reflect.Indirect(record))
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		c.Values[i] = data
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor decode cursor to sort key values, values are restored in type of fields
+func decodeCursor(keys []cursorKey, s string) (values []interface{}, backward bool, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || len(c.Values) != len(keys) {
+		return nil, false, ErrInvalidCursor
+	}
+
+	values = make([]interface{}, len(keys))
+	for i, key := range keys {
+		value := reflect.New(key.field.FieldType)
+		if err := json.Unmarshal(c.Values[i], value.Interface()); err != nil {
+			return nil, false, ErrInvalidCursor
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return values, c.Backward, nil
+}
+
+// afterCursor condition matches rows after values in order of keys
+func (d *DO) afterCursor(keys []cursorKey, values []interface{}) clause.Expression {
+	sameDirection, nullable := true, false
+	for _, key := range keys {
+		sameDirection = sameDirection && key.desc == keys[0].desc
+		nullable = nullable || key.nullable
+	}
+
+	if len(keys) > 1 && sameDirection && !nullable && rowComparisonDialects[d.db.Dialector.Name()] {
+		placeholders, vars := make([]string, len(keys)), make([]interface{}, 0, 2*len(keys))
+		for i, key := range keys {
+			placeholders[i] = "?"
+			vars = append(vars, key.column)
+		}
+		vars = append(vars, values...)
+		op := ">"
+		if keys[0].desc {
+			op = "<"
+		}
+		tuple := "(" + strings.Join(placeholders, ",") + ")"
+		return clause.Expr{SQL: tuple + " " + op + " " + tuple, Vars: vars}
+	}
+
+	// expanded form: (a > ?) OR (a = ? AND b > ?) OR ...
+	terms := make([]clause.Expression, 0, len(keys))
+	for i, key := range keys {
+		after := key.after(values[i])
+		if after != nil {
+			conds := make([]clause.Expression, 0, i+1)
+			for j := 0; j < i; j++ {
+				conds = append(conds, keys[j].equal(values[j]))
+			}
+			terms = append(terms, clause.And(append(conds, after)...))
+		}
+	}
+	if len(terms) == 0 {
+		return clause.Expr{SQL: "1 = 0"}
+	}
+	// wrapped with AND, OR conditions at top level of WHERE are joined with OR by GORM
+	return clause.AndConditions{Exprs: []clause.Expression{clause.Or(terms...)}}
+}
+
+// FindByCursor find a page of records after (or before) cursor in order of columns (keyset pagination),
+// cursor is empty for the first page, columns replace existing order and should identify a record uniquely,
+// eg: FindByCursor(cursor, 20, u.CreatedAt.Desc(), u.ID.Desc())
+func (d *DO) FindByCursor(cursor string, limit int, columns ...field.Expr) (results interface{}, page CursorPage, err error) {
+	if ok, err := d.intercept("FindByCursor",
+		func(d *DO) (err error) { results, page, err = d.FindByCursor(cursor, limit, columns...); return err },
+		func(d *DO) error { _, _, err := d.FindByCursor(cursor, limit, columns...); return err },
+	); ok {
+		return d.orEmptySlice(results), page, err
+	}
+	if limit <= 0 {
+		return nil, page, fmt.Errorf("cursor pagination: invalid limit %d", limit)
+	}
+	keys, err := d.cursorKeys(columns)
+	if err != nil {
+		return nil, page, err
+	}
+
+	var (
+		values   []interface{}
+		backward bool
+	)
+	if cursor != "" {
+		if values, backward, err = decodeCursor(keys, cursor); err != nil {
+			return nil, page, err
+		}
+	}
+
+	orderKeys := keys
+	if backward {
+		orderKeys = make([]cursorKey, len(keys))
+		for i, key := range keys {
+			orderKeys[i] = key.reverse()
+		}
+	}
+	orders := make([]clause.Expression, 0, 2*len(orderKeys))
+	for _, key := range orderKeys {
+		orders = append(orders, key.orderBy()...)
+	}
+
+	tx := d.db.Session(new(gorm.Session)).Clauses(clause.OrderBy{Expression: clause.CommaExpression{Exprs: orders}}).Limit(limit + 1)
+	if values != nil {
+		tx = tx.Where(d.afterCursor(orderKeys, values))
+	}
+	results, err = d.getInstance(tx).Find()
+	if err != nil {
+		return nil, page, err
+	}
+
+	rv := reflect.ValueOf(results)
+	hasMore := rv.Len() > limit
+	if hasMore {
+		rv = rv.Slice(0, limit)
+	}
+	if backward {
+		for i, j := 0, rv.Len()-1; i < j; i, j = i+1, j-1 {
+			tmp := rv.Index(i).Interface()
+			rv.Index(i).Set(rv.Index(j))
+			rv.Index(j).Set(reflect.ValueOf(tmp))
+		}
+	}
+
+	if n := rv.Len(); n > 0 {
+		if hasMore || backward {
+			if page.Next, err = encodeCursor(keys, rv.Index(n-1), false); err != nil {
+				return nil, page, err
+			}
+		}
+		if hasMore && backward || !backward && values != nil {
+			if page.Prev, err = encodeCursor(keys, rv.Index(0), true); err != nil {
+				return nil, page, err
+			}
+		}
+	}
+	return rv.Interface(), page, nil
+}
@@ -30,10 +30,23 @@ type resultInfo struct {
 // DO (data object): implement basic query methods
 // the structure embedded with a *gorm.DB, and has a element item "alias" will be used when used as a sub query
 type DO struct {
-	db     *gorm.DB
-	alias  string // for subquery
-	model  interface{}
-	schema *schema.Schema
+	db         *gorm.DB
+	alias      string // for subquery
+	tableAlias string // for table aliased by UseAlias
+	model      interface{}
+	schema     *schema.Schema
+
+	softDelete *softDelete
+	tenant     *tenantScope
+	sharding   *sharding
+
+	version         *versionLock
+	expectedVersion interface{}
+	checkVersion    bool
+
+	conflictColumns []clause.Column
+
+	interceptors []Interceptor
 }
 
 func (d DO) getInstance(db *gorm.DB) *DO {
@@ -57,7 +70,25 @@ func (d *DO) UseDB(db *gorm.DB, opts ...doOptions) {
 	d.db = db
 }
 
-func (d *DO) ReplaceDB(db *gorm.DB) { d.db = db }
+// ReplaceDB replace db of DO, eg: with transaction, table and scopes of model and table alias are kept
+func (d *DO) ReplaceDB(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// copy statement of db before table of model is set, so that db is not changed
+	db = db.Session(&gorm.Session{Context: ctx})
+	db.Statement.Schema, db.Statement.Table, db.Statement.TableExpr = d.db.Statement.Schema, d.db.Statement.Table, d.db.Statement.TableExpr
+	d.db = d.withScopes(db)
+	if d.tableAlias != "" {
+		d.UseAlias(d.tableAlias)
+	}
+}
+
+// withScopes register statement scopes of model on db
+func (d *DO) withScopes(db *gorm.DB) *gorm.DB {
+	return d.tenant.withScope(d.sharding.withScope(d.softDelete.withScope(db)))
+}
 
 // UseModel specify a data model structure as a source for table name
 func (d *DO) UseModel(model interface{}) {
@@ -68,11 +99,31 @@ func (d *DO) UseModel(model interface{}) {
 		panic(fmt.Errorf("Cannot parse model: %+v", model))
 	}
 	d.schema = d.db.Statement.Schema
+
+	d.softDelete, err = parseSoftDelete(d.schema)
+	if err != nil {
+		panic(fmt.Errorf("Cannot parse model: %w", err))
+	}
+	d.db = d.softDelete.withScope(d.db)
+
+	d.version = parseVersionLock(d.schema)
+
+	if sharder, ok := model.(Sharder); ok {
+		d.UseSharding(sharder.Sharding())
+	}
 }
 
 // UseTable specify table name
 func (d *DO) UseTable(tableName string) { d.db = d.db.Table(tableName).Session(new(gorm.Session)) }
 
+// UseAlias specify alias of table, the table is queried as FROM `table` AS `alias` and joined as JOIN `table` AS `alias`
+func (d *DO) UseAlias(alias string) {
+	d.tableAlias = alias
+	db := d.db.Table("? AS ?", clause.Table{Name: d.TableName()}, clause.Table{Name: alias})
+	db.Statement.Table = alias
+	d.db = db.Session(new(gorm.Session))
+}
+
 // TableName return table name
 func (d DO) TableName() string {
 	if d.schema == nil {
@@ -81,6 +132,14 @@ func (d DO) TableName() string {
 	return d.schema.Table
 }
 
+// Alias return alias of subquery or table
+func (d DO) Alias() string {
+	if d.alias != "" {
+		return d.alias
+	}
+	return d.tableAlias
+}
+
 // Session replace db with new session
 func (d *DO) Session(config *gorm.Session) Dao { return d.getInstance(d.db.Session(config)) }
 
@@ -172,7 +231,7 @@ func (d *DO) Select(columns ...field.Expr) Dao {
 		return d.getInstance(d.db.Clauses(clause.Select{}))
 	}
 	query, args := buildExpr(d.db.Statement, columns...)
-	return d.getInstance(d.db.Select(query, args...))
+	return d.getInstance(keepBeforeSelect(d.db, func(db *gorm.DB) *gorm.DB { return db.Select(query, args...) }))
 }
 
 func (d *DO) Where(conds ...Condition) Dao {
@@ -200,6 +259,9 @@ func (d *DO) Order(columns ...field.Expr) Dao {
 	if len(columns) == 0 {
 		return d
 	}
+	if query, args := buildExpr(d.db.Statement, columns...); len(args) > 0 || hasExprs(d.db, "ORDER BY") {
+		return d.getInstance(withExprs(d.db, "ORDER BY", []clause.Expression{clause.Expr{SQL: query, Vars: args}}))
+	}
 	return d.getInstance(d.db.Order(d.calcOrderValue(columns...)))
 }
 
@@ -213,7 +275,9 @@ func (d *DO) calcOrderValue(columns ...field.Expr) string {
 }
 
 func (d *DO) Distinct(columns ...field.Expr) Dao {
-	return d.getInstance(d.db.Distinct(toInterfaceSlice(toColumnFullName(d.db.Statement, columns...))...))
+	return d.getInstance(keepBeforeSelect(d.db, func(db *gorm.DB) *gorm.DB {
+		return db.Distinct(toInterfaceSlice(toColumnFullName(db.Statement, columns...))...)
+	}))
 }
 
 func (d *DO) Omit(columns ...field.Expr) Dao {
@@ -228,6 +292,10 @@ func (d *DO) Group(columns ...field.Expr) Dao {
 		return d
 	}
 
+	if query, args := buildExpr(d.db.Statement, columns...); len(args) > 0 || hasExprs(d.db, "GROUP BY") {
+		return d.getInstance(withExprs(d.db, "GROUP BY", []clause.Expression{clause.Expr{SQL: query, Vars: args}}))
+	}
+
 	name := columns[0].BuildColumn(d.db.Statement, field.WithTable).String()
 	for _, col := range columns[1:] {
 		name += "," + col.BuildColumn(d.db.Statement, field.WithTable).String()
@@ -268,6 +336,22 @@ func (d *DO) Unscoped() Dao {
 	return d.getInstance(d.db.Unscoped())
 }
 
+// WithDeleted return a DO which queries both alive and soft deleted rows
+func (d *DO) WithDeleted() Dao {
+	if d.softDelete == nil {
+		return d.withError(ErrNoSoftDelete)
+	}
+	return d.getInstance(d.db.Unscoped())
+}
+
+// OnlyDeleted return a DO which queries soft deleted rows only
+func (d *DO) OnlyDeleted() Dao {
+	if d.softDelete == nil {
+		return d.withError(ErrNoSoftDelete)
+	}
+	return d.getInstance(d.db.Unscoped().Where(d.softDelete.deletedCond()))
+}
+
 func (d *DO) Join(table schema.Tabler, conds ...field.Expr) Dao {
 	return d.join(table, clause.InnerJoin, conds)
 }
@@ -285,15 +369,47 @@ func (d *DO) join(table schema.Tabler, joinType clause.JoinType, conds []field.E
 		return d.withError(ErrEmptyCondition)
 	}
 
-	from := getFromClause(d.db)
-	from.Joins = append(from.Joins, clause.Join{
+	join := clause.Join{
 		Type:  joinType,
 		Table: clause.Table{Name: table.TableName()},
 		ON:    clause.Where{Exprs: toExpression(conds...)},
-	})
+	}
+	if query, ok := table.(subQuery); ok && query.underlyingDO().alias != "" {
+		// subquery aliased by As is joined as derived table
+		do := query.underlyingDO()
+		if err := do.db.Error; err != nil {
+			return d.withError(err)
+		}
+		join.Expression = joinSubQuery{Join: join, query: do.db, alias: do.alias}
+	} else if aliased, ok := table.(interface{ Alias() string }); ok && aliased.Alias() != "" {
+		join.Table = clause.Table{Name: d.Quote(join.Table.Name) + " AS " + d.Quote(aliased.Alias()), Raw: true}
+	}
+
+	from := getFromClause(d.db)
+	from.Joins = append(from.Joins, join)
+	if cte, ok := table.(*CTE); ok {
+		return d.getInstance(d.db.Clauses(from, withClause{ctes: []*CTE{cte}}))
+	}
 	return d.getInstance(d.db.Clauses(from))
 }
 
+// joinSubQuery JOIN clause of subquery, eg: INNER JOIN (SELECT ...) AS `alias` ON ...
+type joinSubQuery struct {
+	clause.Join
+	query *gorm.DB
+	alias string
+}
+
+func (j joinSubQuery) Build(builder clause.Builder) {
+	builder.WriteString(string(j.Type))
+	builder.WriteString(" JOIN (")
+	builder.AddVar(builder, j.query)
+	builder.WriteString(") AS ")
+	builder.WriteQuoted(j.alias)
+	builder.WriteString(" ON ")
+	j.ON.Build(builder)
+}
+
 func (d *DO) Attrs(attrs ...field.AssignExpr) Dao {
 	if len(attrs) == 0 {
 		return d
@@ -364,27 +480,79 @@ func getFromClause(db *gorm.DB) *clause.From {
 
 // ======================== finisher api ========================
 func (d *DO) Create(value interface{}) error {
-	return d.db.Create(value).Error
+	create := func(d *DO) error { return d.Create(value) }
+	if ok, err := d.intercept("Create", create, create); ok {
+		return err
+	}
+	defer d.invalidateCache()
+	if err := d.assignTenant(value); err != nil {
+		return err
+	}
+	return d.shardCreate(d.db, value, func(tx *gorm.DB, value interface{}) error { return tx.Create(value).Error })
 }
 
 func (d *DO) CreateInBatches(value interface{}, batchSize int) error {
-	return d.db.CreateInBatches(value, batchSize).Error
+	createInBatches := func(d *DO) error { return d.CreateInBatches(value, batchSize) }
+	if ok, err := d.intercept("CreateInBatches", createInBatches, createInBatches); ok {
+		return err
+	}
+	defer d.invalidateCache()
+	if err := d.assignTenant(value); err != nil {
+		return err
+	}
+	return d.shardCreate(d.db, value, func(tx *gorm.DB, value interface{}) error {
+		return tx.CreateInBatches(value, batchSize).Error
+	})
 }
 
 func (d *DO) Save(value interface{}) error {
-	return d.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(value).Error
+	save := func(d *DO) error { return d.Save(value) }
+	if ok, err := d.intercept("Save", save, save); ok {
+		return err
+	}
+	defer d.invalidateCache()
+	if err := d.assignTenant(value); err != nil {
+		return err
+	}
+	if d.version != nil {
+		return d.saveWithVersion(value)
+	}
+	if d.tenant != nil && !d.tenant.unscoped(d.db) {
+		return d.saveWithTenant(value)
+	}
+	return d.shardCreate(d.db, value, func(tx *gorm.DB, value interface{}) error {
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(value).Error
+	})
 }
 
 func (d *DO) First() (result interface{}, err error) {
-	return d.singleQuery(d.db.First)
+	if ok, err := d.intercept("First",
+		func(d *DO) (err error) { result, err = d.First(); return err },
+		func(d *DO) error { _, err := d.First(); return err },
+	); ok {
+		return result, err
+	}
+	return d.singleQuery(d.cacheQuery((*gorm.DB).First))
 }
 
 func (d *DO) Take() (result interface{}, err error) {
-	return d.singleQuery(d.db.Take)
+	if ok, err := d.intercept("Take",
+		func(d *DO) (err error) { result, err = d.Take(); return err },
+		func(d *DO) error { _, err := d.Take(); return err },
+	); ok {
+		return result, err
+	}
+	return d.singleQuery(d.cacheQuery((*gorm.DB).Take))
 }
 
 func (d *DO) Last() (result interface{}, err error) {
-	return d.singleQuery(d.db.Last)
+	if ok, err := d.intercept("Last",
+		func(d *DO) (err error) { result, err = d.Last(); return err },
+		func(d *DO) error { _, err := d.Last(); return err },
+	); ok {
+		return result, err
+	}
+	return d.singleQuery(d.readDB().Last)
 }
 
 func (d *DO) singleQuery(query func(dest interface{}, conds ...interface{}) *gorm.DB) (result interface{}, err error) {
@@ -401,12 +569,21 @@ func (d *DO) singleQuery(query func(dest interface{}, conds ...interface{}) *gor
 
 func (d *DO) singleScan() (result interface{}, err error) {
 	result = map[string]interface{}{}
-	err = d.db.Scan(result).Error
+	err = d.readDB().Scan(result).Error
 	return
 }
 
 func (d *DO) Find() (results interface{}, err error) {
-	return d.multiQuery(d.db.Find)
+	if ok, err := d.intercept("Find",
+		func(d *DO) (err error) { results, err = d.Find(); return err },
+		func(d *DO) error { _, err := d.Find(); return err },
+	); ok {
+		return d.orEmptySlice(results), err
+	}
+	if d.fanOut() {
+		return d.fanOutFind()
+	}
+	return d.multiQuery(d.cacheQuery((*gorm.DB).Find))
 }
 
 func (d *DO) multiQuery(query func(dest interface{}, conds ...interface{}) *gorm.DB) (results interface{}, err error) {
@@ -421,45 +598,87 @@ func (d *DO) multiQuery(query func(dest interface{}, conds ...interface{}) *gorm
 
 func (d *DO) findToMap() (interface{}, error) {
 	var results []map[string]interface{}
-	err := d.db.Find(&results).Error
+	err := d.readDB().Find(&results).Error
 	return results, err
 }
 
 func (d *DO) FindInBatch(batchSize int, fc func(tx Dao, batch int) error) (result interface{}, err error) {
+	if ok, err := d.intercept("FindInBatch",
+		func(d *DO) (err error) { result, err = d.FindInBatch(batchSize, fc); return err },
+		func(d *DO) error { _, err := d.FindInBatch(batchSize, fc); return err },
+	); ok {
+		return d.orEmptySlice(result), err
+	}
 	resultsPtr := d.newResultSlicePointer()
-	err = d.db.FindInBatches(resultsPtr, batchSize, func(tx *gorm.DB, batch int) error { return fc(d.getInstance(tx), batch) }).Error
+	err = d.readDB().FindInBatches(resultsPtr, batchSize, func(tx *gorm.DB, batch int) error { return fc(d.getInstance(tx), batch) }).Error
 	return reflect.Indirect(reflect.ValueOf(resultsPtr)).Interface(), err
 }
 
 func (d *DO) FindInBatches(dest interface{}, batchSize int, fc func(tx Dao, batch int) error) error {
-	return d.db.FindInBatches(dest, batchSize, func(tx *gorm.DB, batch int) error { return fc(d.getInstance(tx), batch) }).Error
+	findInBatches := func(d *DO) error { return d.FindInBatches(dest, batchSize, fc) }
+	if ok, err := d.intercept("FindInBatches", findInBatches, findInBatches); ok {
+		return err
+	}
+	return d.readDB().FindInBatches(dest, batchSize, func(tx *gorm.DB, batch int) error { return fc(d.getInstance(tx), batch) }).Error
 }
 
 func (d *DO) FirstOrInit() (result interface{}, err error) {
-	return d.singleQuery(d.db.FirstOrInit)
+	if ok, err := d.intercept("FirstOrInit",
+		func(d *DO) (err error) { result, err = d.FirstOrInit(); return err },
+		func(d *DO) error { _, err := d.FirstOrInit(); return err },
+	); ok {
+		return result, err
+	}
+	return d.singleQuery(d.readDB().FirstOrInit)
 }
 
 func (d *DO) FirstOrCreate() (result interface{}, err error) {
+	if ok, err := d.intercept("FirstOrCreate",
+		func(d *DO) (err error) { result, err = d.FirstOrCreate(); return err },
+		func(d *DO) error { _, err := d.FirstOrCreate(); return err },
+	); ok {
+		return result, err
+	}
+	if d.sharding != nil {
+		return d.shardFirstOrCreate()
+	}
+	defer d.invalidateCache()
 	return d.singleQuery(d.db.FirstOrCreate)
 }
 
 func (d *DO) Update(column field.Expr, value interface{}) (info resultInfo, err error) {
+	if ok, err := d.intercept("Update",
+		func(d *DO) (err error) { info, err = d.Update(column, value); return err },
+		func(d *DO) error { _, err := d.Update(column, value); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
 	tx := d.db.Model(d.model)
 	columnStr := column.BuildColumn(d.db.Statement, field.WithoutQuote).String()
 
-	var result *gorm.DB
-	switch value := value.(type) {
+	switch v := value.(type) {
 	case field.AssignExpr:
-		result = tx.Update(columnStr, value.AssignExpr())
+		value = v.AssignExpr()
 	case subQuery:
-		result = tx.Update(columnStr, value.underlyingDB())
-	default:
-		result = tx.Update(columnStr, value)
+		value = v.underlyingDB()
+	}
+	if d.version != nil {
+		return d.updatesWithVersion(tx, map[string]interface{}{columnStr: value}, d.expectedVersion, d.checkVersion)
 	}
+
+	result := tx.Update(columnStr, value)
 	return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
 }
 
 func (d *DO) UpdateSimple(columns ...field.AssignExpr) (info resultInfo, err error) {
+	if ok, err := d.intercept("UpdateSimple",
+		func(d *DO) (err error) { info, err = d.UpdateSimple(columns...); return err },
+		func(d *DO) error { _, err := d.UpdateSimple(columns...); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
 	if len(columns) == 0 {
 		return
 	}
@@ -469,19 +688,58 @@ func (d *DO) UpdateSimple(columns ...field.AssignExpr) (info resultInfo, err err
 		return resultInfo{Error: err}, err
 	}
 
+	if d.version != nil {
+		return d.updatesWithVersion(d.db.Model(d.model), dest, d.expectedVersion, d.checkVersion)
+	}
+
 	result := d.db.Model(d.model).Updates(dest)
 	return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
 }
 
 func (d *DO) Updates(value interface{}) (info resultInfo, err error) {
+	if ok, err := d.intercept("Updates",
+		func(d *DO) (err error) { info, err = d.Updates(value); return err },
+		func(d *DO) error { _, err := d.Updates(value); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
+	if d.version != nil {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return d.updatesWithVersion(d.db.Model(d.model), v, d.expectedVersion, d.checkVersion)
+		default:
+			if rv := reflect.Indirect(reflect.ValueOf(value)); rv.IsValid() && rv.Type() == d.getModelType() {
+				return d.updateModelWithVersion(value, (*gorm.DB).Updates)
+			}
+		}
+	}
+
 	result := d.db.Model(d.model).Updates(value)
 	return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
 }
 
 func (d *DO) UpdateColumn(column field.Expr, value interface{}) (info resultInfo, err error) {
+	if ok, err := d.intercept("UpdateColumn",
+		func(d *DO) (err error) { info, err = d.UpdateColumn(column, value); return err },
+		func(d *DO) error { _, err := d.UpdateColumn(column, value); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
 	tx := d.db.Model(d.model)
 	columnStr := column.BuildColumn(d.db.Statement, field.WithoutQuote).String()
 
+	if d.version != nil {
+		switch v := value.(type) {
+		case field.Expr:
+			value = v.RawExpr()
+		case subQuery:
+			value = v.underlyingDB()
+		}
+		return d.updateColumnsWithVersion(tx, map[string]interface{}{columnStr: value}, d.expectedVersion, d.checkVersion)
+	}
+
 	var result *gorm.DB
 	switch value := value.(type) {
 	case field.Expr:
@@ -495,6 +753,13 @@ func (d *DO) UpdateColumn(column field.Expr, value interface{}) (info resultInfo
 }
 
 func (d *DO) UpdateColumnSimple(columns ...field.AssignExpr) (info resultInfo, err error) {
+	if ok, err := d.intercept("UpdateColumnSimple",
+		func(d *DO) (err error) { info, err = d.UpdateColumnSimple(columns...); return err },
+		func(d *DO) error { _, err := d.UpdateColumnSimple(columns...); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
 	if len(columns) == 0 {
 		return
 	}
@@ -504,41 +769,143 @@ func (d *DO) UpdateColumnSimple(columns ...field.AssignExpr) (info resultInfo, e
 		return resultInfo{Error: err}, err
 	}
 
+	if d.version != nil {
+		return d.updateColumnsWithVersion(d.db.Model(d.model), dest, d.expectedVersion, d.checkVersion)
+	}
+
 	result := d.db.Model(d.model).UpdateColumns(dest)
 	return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
 }
 
 func (d *DO) UpdateColumns(value interface{}) (info resultInfo, err error) {
+	if ok, err := d.intercept("UpdateColumns",
+		func(d *DO) (err error) { info, err = d.UpdateColumns(value); return err },
+		func(d *DO) error { _, err := d.UpdateColumns(value); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
+	if d.version != nil {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return d.updateColumnsWithVersion(d.db.Model(d.model), v, d.expectedVersion, d.checkVersion)
+		default:
+			if rv := reflect.Indirect(reflect.ValueOf(value)); rv.IsValid() && rv.Type() == d.getModelType() {
+				return d.updateModelWithVersion(value, (*gorm.DB).UpdateColumns)
+			}
+		}
+	}
+
 	result := d.db.Model(d.model).UpdateColumns(value)
 	return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
 }
 
 func (d *DO) Delete() (info resultInfo, err error) {
+	if ok, err := d.intercept("Delete",
+		func(d *DO) (err error) { info, err = d.Delete(); return err },
+		func(d *DO) error { _, err := d.Delete(); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
+	if sd := d.softDelete; sd != nil && !sd.native() && !d.db.Statement.Unscoped {
+		result := d.db.Model(d.model).UpdateColumn(sd.column, sd.deletedValue())
+		return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	}
+
 	result := d.db.Model(d.model).Delete(reflect.New(d.getModelType()).Interface())
 	return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
 }
 
+// ForceDelete delete matched rows permanently even if model has soft delete field
+func (d *DO) ForceDelete() (info resultInfo, err error) {
+	if ok, err := d.intercept("ForceDelete",
+		func(d *DO) (err error) { info, err = d.ForceDelete(); return err },
+		func(d *DO) error { _, err := d.ForceDelete(); return err },
+	); ok {
+		return info, err
+	}
+	return d.getInstance(d.db.Unscoped()).Delete()
+}
+
+// Restore restore matched soft deleted rows, conditions are required
+func (d *DO) Restore() (info resultInfo, err error) {
+	if ok, err := d.intercept("Restore",
+		func(d *DO) (err error) { info, err = d.Restore(); return err },
+		func(d *DO) error { _, err := d.Restore(); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
+	sd := d.softDelete
+	if sd == nil {
+		return resultInfo{Error: ErrNoSoftDelete}, ErrNoSoftDelete
+	}
+	if _, ok := d.db.Statement.Clauses[clause.Where{}.Name()]; !ok {
+		return resultInfo{Error: gorm.ErrMissingWhereClause}, gorm.ErrMissingWhereClause
+	}
+
+	result := d.db.Unscoped().Model(d.model).Where(sd.deletedCond()).UpdateColumn(sd.column, sd.aliveValue())
+	return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+}
+
 func (d *DO) Count() (count int64, err error) {
-	return count, d.db.Session(&gorm.Session{}).Model(d.model).Count(&count).Error
+	if ok, err := d.intercept("Count",
+		func(d *DO) (err error) { count, err = d.Count(); return err },
+		func(d *DO) error { _, err := d.Count(); return err },
+	); ok {
+		return count, err
+	}
+	if d.fanOut() {
+		return d.fanOutCount()
+	}
+	query := d.cacheQuery(func(db *gorm.DB, dest interface{}, _ ...interface{}) *gorm.DB {
+		return db.Session(&gorm.Session{}).Model(d.model).Count(dest.(*int64))
+	})
+	return count, query(&count).Error
 }
 
-func (d *DO) Row() *sql.Row {
-	return d.db.Model(d.model).Row()
+// Row return the first row of query, nil if it is short-circuited by interceptors
+func (d *DO) Row() (row *sql.Row) {
+	if ok, _ := d.intercept("Row",
+		func(d *DO) error { row = d.Row(); return row.Err() },
+		// gorm logs error for Row in dry run, render the same statement by Rows
+		func(d *DO) error { _, err := d.Rows(); return err },
+	); ok {
+		return row
+	}
+	return d.readDB().Model(d.model).Row()
 }
 
-func (d *DO) Rows() (*sql.Rows, error) {
-	return d.db.Model(d.model).Rows()
+func (d *DO) Rows() (rows *sql.Rows, err error) {
+	// rows are not rendered in advance, which are consumed by the caller
+	if ok, err := d.intercept("Rows", func(d *DO) (err error) { rows, err = d.Rows(); return err }, nil); ok {
+		return rows, err
+	}
+	return d.readDB().Model(d.model).Rows()
 }
 
 func (d *DO) Scan(dest interface{}) error {
-	return d.db.Model(d.model).Scan(dest).Error
+	scan := func(d *DO) error { return d.Scan(dest) }
+	if ok, err := d.intercept("Scan", scan, scan); ok {
+		return err
+	}
+	return d.readDB().Model(d.model).Scan(dest).Error
 }
 
 func (d *DO) Pluck(column field.Expr, dest interface{}) error {
-	return d.db.Model(d.model).Pluck(column.ColumnName().String(), dest).Error
+	pluck := func(d *DO) error { return d.Pluck(column, dest) }
+	if ok, err := d.intercept("Pluck", pluck, pluck); ok {
+		return err
+	}
+	return d.readDB().Model(d.model).Pluck(column.ColumnName().String(), dest).Error
 }
 
 func (d *DO) ScanRows(rows *sql.Rows, dest interface{}) error {
+	// rows of the caller are scanned once, SQL is not rendered
+	if ok, err := d.intercept("ScanRows", func(d *DO) error { return d.ScanRows(rows, dest) }, nil); ok {
+		return err
+	}
 	return d.db.Model(d.model).ScanRows(rows, dest)
 }
 
@@ -656,7 +1023,7 @@ func assignMap(stmt *gorm.Statement, exprs []field.AssignExpr) (map[string]inter
 // ======================== New Table ========================
 
 // Table return a new table produced by subquery,
-// the return value has to be used as root node
+// the return value has to be used as root node, CTE is referred by its name
 //
 // 	Table(u.Select(u.ID, u.Name).Where(u.Age.Gt(18))).Select()
 // the above usage is equivalent to SQL statement:
@@ -667,21 +1034,29 @@ func Table(subQueries ...subQuery) Dao {
 	}
 
 	tablePlaceholder := make([]string, len(subQueries))
-	tableExprs := make([]interface{}, len(subQueries))
+	tableExprs := make([]interface{}, 0, len(subQueries))
+	var ctes []*CTE
 	for i, query := range subQueries {
-		tablePlaceholder[i] = "(?)"
-
 		do := query.underlyingDO()
-		tableExprs[i] = do.db
+		if cte, ok := query.(*CTE); ok {
+			tablePlaceholder[i] = do.Quote(cte.name)
+			ctes = append(ctes, cte)
+			continue
+		}
+
+		tablePlaceholder[i] = "(?)"
+		tableExprs = append(tableExprs, do.db)
 		if do.alias != "" {
 			tablePlaceholder[i] += " AS " + do.Quote(do.alias)
 		}
 	}
 
-	return &DO{
-		db: subQueries[0].underlyingDO().db.Session(&gorm.Session{NewDB: true}).
-			Table(strings.Join(tablePlaceholder, ", "), tableExprs...),
+	db := subQueries[0].underlyingDO().db.Session(&gorm.Session{NewDB: true}).
+		Table(strings.Join(tablePlaceholder, ", "), tableExprs...)
+	if len(ctes) > 0 {
+		db = db.Clauses(withClause{ctes: ctes})
 	}
+	return &DO{db: db}
 }
 
 // ======================== sub query method ========================
@@ -1,13 +1,25 @@
 package gen
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/datatypes"
+	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
 	"gorm.io/hints"
 
 	"gorm.io/gen/field"
@@ -66,6 +78,1876 @@ func build(stmt *gorm.Statement, opts ...stmtOpt) *gorm.Statement {
 	return stmt
 }
 
+func TestDO_softDelete(t *testing.T) {
+	if _, err := u.OnlyDeleted().Find(); err != ErrNoSoftDelete {
+		t.Errorf("OnlyDeleted on model without soft delete field expects %v got %v", ErrNoSoftDelete, err)
+	}
+	if _, err := account.Restore(); err != gorm.ErrMissingWhereClause {
+		t.Errorf("Restore without conditions expects %v got %v", gorm.ErrMissingWhereClause, err)
+	}
+	if account.softDelete == nil || account.softDelete.mode != SoftDeleteFlag || account.softDelete.column != "is_deleted" {
+		t.Errorf("parse soft delete column fail: %+v", account.softDelete)
+	}
+}
+
+// testPluginDeletedAt soft delete type applying clauses by itself like soft_delete.DeletedAt of gorm.io/plugin/soft_delete
+type testPluginDeletedAt uint
+
+func (testPluginDeletedAt) QueryClauses(*schema.Field) []clause.Interface  { return nil }
+func (testPluginDeletedAt) DeleteClauses(*schema.Field) []clause.Interface { return nil }
+
+func TestDO_softDeleteMode(t *testing.T) {
+	type milliRaw struct {
+		ID        int64
+		DeletedAt int64 `gorm:"softDelete:milli"`
+	}
+	type secondRaw struct {
+		ID        int64
+		DeletedAt int64 `gorm:"softDelete"`
+	}
+	type pluginRaw struct {
+		ID        int64
+		DeletedAt testPluginDeletedAt `gorm:"softDelete:nano"`
+	}
+	type unknownRaw struct {
+		ID        int64
+		DeletedAt int64 `gorm:"softDelete:micro"`
+	}
+
+	testcases := []struct {
+		Model    interface{}
+		Mode     SoftDeleteMode
+		Expected string
+	}{
+		{Model: milliRaw{}, Mode: SoftDeleteMilli, Expected: "SELECT * FROM `milli_raws` WHERE `milli_raws`.`deleted_at` = ?"},
+		{Model: secondRaw{}, Mode: SoftDeleteUnix, Expected: "SELECT * FROM `second_raws` WHERE `second_raws`.`deleted_at` = ?"},
+		// scope is applied by the type itself, it should not be applied twice
+		{Model: pluginRaw{}, Mode: SoftDeleteNano, Expected: "SELECT * FROM `plugin_raws`"},
+	}
+	for _, testcase := range testcases {
+		var d DO
+		d.UseDB(db.Session(&gorm.Session{DryRun: true}))
+		d.UseModel(testcase.Model)
+		if d.softDelete == nil || d.softDelete.mode != testcase.Mode {
+			t.Errorf("%T expects soft delete mode %s got %+v", testcase.Model, testcase.Mode, d.softDelete)
+			continue
+		}
+		if sql := d.db.Find(reflect.New(reflect.TypeOf(testcase.Model)).Interface()).Statement.SQL.String(); sql != testcase.Expected {
+			t.Errorf("%T expects SQL %q got %q", testcase.Model, testcase.Expected, sql)
+		}
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	_ = stmt.Parse(&unknownRaw{})
+	if _, err := parseSoftDelete(stmt.Schema); err == nil || !strings.Contains(err.Error(), "micro") {
+		t.Errorf("unknown softDelete tag expects error got %v", err)
+	}
+}
+
+func TestDO_optimisticLock(t *testing.T) {
+	if _, err := u.WithVersion(1).Find(); !errors.Is(err, ErrNoVersion) {
+		t.Errorf("WithVersion on model without version field expects %v got %v", ErrNoVersion, err)
+	}
+
+	// no row will be affected in dry run mode
+	if _, err := account.WithVersion(1).UpdateSimple(account.Name.Value("tom")); !errors.Is(err, ErrOptimisticLock) {
+		t.Errorf("UpdateSimple with expected version expects %v got %v", ErrOptimisticLock, err)
+	}
+	if _, err := account.Where(account.ID.Eq(1)).UpdateSimple(account.Name.Value("tom")); err != nil {
+		t.Errorf("UpdateSimple without expected version expects no error got %v", err)
+	}
+
+	record := &AccountRaw{ID: 1, Name: "tom", Version: 2}
+	if _, err := account.Updates(record); !errors.Is(err, ErrOptimisticLock) {
+		t.Errorf("Updates loaded record expects %v got %v", ErrOptimisticLock, err)
+	}
+	if record.Version != 2 {
+		t.Errorf("version should not be increased when update fail, got %d", record.Version)
+	}
+	if err := account.Save([]*AccountRaw{record}); !errors.Is(err, ErrOptimisticLock) {
+		t.Errorf("Save loaded record expects %v got %v", ErrOptimisticLock, err)
+	}
+	if err := account.Save([]*AccountRaw{{Name: "new"}}); err != nil {
+		t.Errorf("Save new record expects no error got %v", err)
+	}
+
+	// created time and soft delete column are not written, soft delete scope is kept
+	testcases := []struct {
+		Name     string
+		Finisher func(tx Dao) error
+		Expected string
+	}{
+		{
+			Name:     "Save",
+			Finisher: func(tx Dao) error { return tx.Save(&AccountRaw{ID: 1, Name: "tom", Version: 2}) },
+			Expected: "UPDATE `account` SET `name`=\"tom\",`version`=`version` + 1 WHERE `account`.`version` = 2 AND `account`.`is_deleted` = 0 AND `id` = 1",
+		},
+		{
+			Name:     "Updates",
+			Finisher: func(tx Dao) error { _, err := tx.Updates(&AccountRaw{ID: 1, Name: "tom", Version: 2}); return err },
+			Expected: "UPDATE `account` SET `name`=\"tom\",`version`=`version` + 1 WHERE `account`.`version` = 2 AND `account`.`is_deleted` = 0 AND `id` = 1",
+		},
+		{
+			Name: "WithVersion",
+			Finisher: func(tx Dao) error {
+				_, err := tx.WithVersion(3).Where(account.ID.Eq(1)).UpdateSimple(account.Name.Value("tom"))
+				return err
+			},
+			Expected: "UPDATE `account` SET `name`=\"tom\",`version`=`version` + 1 WHERE `account`.`id` = 1 AND `account`.`version` = 3 AND `account`.`is_deleted` = 0",
+		},
+		{
+			Name: "UpdateColumn WithVersion",
+			Finisher: func(tx Dao) error {
+				_, err := tx.WithVersion(3).Where(account.ID.Eq(1)).UpdateColumn(account.Name, "tom")
+				return err
+			},
+			Expected: "UPDATE `account` SET `name`=\"tom\",`version`=`version` + 1 WHERE `account`.`id` = 1 AND `account`.`version` = 3 AND `account`.`is_deleted` = 0",
+		},
+		{
+			Name: "UpdateColumns",
+			Finisher: func(tx Dao) error {
+				_, err := tx.UpdateColumns(&AccountRaw{ID: 1, Name: "tom", Version: 2})
+				return err
+			},
+			Expected: "UPDATE `account` SET `name`=\"tom\",`version`=`version` + 1 WHERE `account`.`version` = 2 AND `account`.`is_deleted` = 0 AND `id` = 1",
+		},
+		{
+			// version is increased but not compared without expected version
+			Name: "UpdateColumnSimple by conditions",
+			Finisher: func(tx Dao) error {
+				_, err := tx.Where(account.Name.Eq("tom")).UpdateColumnSimple(account.Name.Value("jerry"))
+				return err
+			},
+			Expected: "UPDATE `account` SET `name`=\"jerry\",`version`=`version` + 1 WHERE `account`.`name` = \"tom\" AND `account`.`is_deleted` = 0",
+		},
+	}
+	for _, testcase := range testcases {
+		stmt, err := account.ToSQL(testcase.Finisher)
+		if err != nil && !errors.Is(err, ErrOptimisticLock) {
+			t.Errorf("%s fail: %v", testcase.Name, err)
+		}
+		if stmt.Interpolated != testcase.Expected {
+			t.Errorf("%s SQL expects %s got %s", testcase.Name, testcase.Expected, stmt.Interpolated)
+		}
+	}
+}
+
+func TestDO_tenant(t *testing.T) {
+	findSQL := func(d Dao) (string, []interface{}, error) {
+		stmt := d.(*DO).db.Find(&[]MemberRaw{}).Statement
+		return stmt.SQL.String(), stmt.Vars, stmt.Error
+	}
+
+	if _, err := member.Find(); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("Find without tenant expects %v got %v", ErrNoTenant, err)
+	}
+	if _, err := u.WithTenant(1).Find(); !errors.Is(err, ErrNoTenantField) {
+		t.Errorf("WithTenant on model without tenant field expects %v got %v", ErrNoTenantField, err)
+	}
+
+	for _, tenant := range []interface{}{int64(7), ContextWithTenant(context.Background(), int64(7))} {
+		sql, vars, err := findSQL(member.WithTenant(tenant).Where(member.Name.Eq("tom")))
+		if err != nil {
+			t.Errorf("Find with tenant %v expects no error got %v", tenant, err)
+		}
+		if expected := "SELECT * FROM `member` WHERE `member`.`name` = ? AND `member`.`tenant_id` = ?"; sql != expected {
+			t.Errorf("SQL expects %q got %q", expected, sql)
+		}
+		if !reflect.DeepEqual(vars, []interface{}{"tom", int64(7)}) {
+			t.Errorf("Vars expects %+v got %+v", []interface{}{"tom", int64(7)}, vars)
+		}
+	}
+	if sql, _, err := findSQL(member.WithTenant(7).UnscopedTenant()); err != nil || sql != "SELECT * FROM `member`" {
+		t.Errorf("UnscopedTenant expects no tenant condition got %q, %v", sql, err)
+	}
+
+	// generated Query.WithTenant clone query structs by ReplaceDB, table of model is kept in subqueries
+	cloned := member.DO
+	cloned.ReplaceDB(WithTenant(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}), int64(7)))
+	named := With("named", cloned.Select(member.ID).Where(member.Name.Neq("")))
+	for _, tc := range []struct {
+		Expr   Dao
+		Result string
+	}{
+		{
+			Expr:   cloned.Select(member.ID).Union(cloned.Select(member.ID)),
+			Result: "SELECT * FROM (SELECT `member`.`id` FROM `member` WHERE `member`.`tenant_id` = 7 UNION SELECT `member`.`id` FROM `member` WHERE `member`.`tenant_id` = 7) AS `member`",
+		},
+		{
+			Expr:   named,
+			Result: "WITH `named` AS (SELECT `member`.`id` FROM `member` WHERE `member`.`name` <> \"\" AND `member`.`tenant_id` = 7) SELECT * FROM `named`",
+		},
+		{
+			Expr:   cloned.Where(columns{member.ID}.In(cloned.Select(member.ID).Where(member.Name.Eq("tom")))),
+			Result: "SELECT * FROM `member` WHERE `member`.`id` IN (SELECT `member`.`id` FROM `member` WHERE `member`.`name` = \"tom\" AND `member`.`tenant_id` = 7) AND `member`.`tenant_id` = 7",
+		},
+	} {
+		stmt, err := tc.Expr.ToSQL(func(tx Dao) error { _, err := tx.Find(); return err })
+		if err != nil || stmt.Interpolated != tc.Result {
+			t.Errorf("query of cloned DO expects %s got %s, %v", tc.Result, stmt.Interpolated, err)
+		}
+	}
+
+	if err := member.Create(&MemberRaw{Name: "tom"}); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("Create without tenant expects %v got %v", ErrNoTenant, err)
+	}
+	record := &MemberRaw{Name: "tom"}
+	if err := member.WithTenant(7).Create([]*MemberRaw{record}); err != nil || record.TenantID != 7 {
+		t.Errorf("Create with tenant expects tenant id assigned got %d, %v", record.TenantID, err)
+	}
+	if err := member.WithTenant(8).Create(record); !errors.Is(err, ErrCrossTenant) {
+		t.Errorf("Create record of other tenant expects %v got %v", ErrCrossTenant, err)
+	}
+	if err := member.UnscopedTenant().Create(record); err != nil {
+		t.Errorf("Create record with UnscopedTenant expects no error got %v", err)
+	}
+	if err := member.WithTenant("7").Create(&MemberRaw{Name: "tom"}); !errors.Is(err, ErrTenantType) {
+		t.Errorf("Create with string tenant id expects %v got %v", ErrTenantType, err)
+	}
+	if err := member.WithTenant(7.5).Create(&MemberRaw{Name: "tom"}); !errors.Is(err, ErrTenantType) {
+		t.Errorf("Create with fractional tenant id expects %v got %v", ErrTenantType, err)
+	}
+	if err := member.WithTenant(uint8(7)).Create(&MemberRaw{Name: "tom"}); err != nil {
+		t.Errorf("Create with number tenant id expects no error got %v", err)
+	}
+
+	// Save updates existing record within current tenant instead of upsert
+	stmt, err := member.WithTenant(7).ToSQL(func(tx Dao) error { return tx.Save(&MemberRaw{ID: 1, Name: "tom"}) })
+	if expected := "UPDATE `member` SET `tenant_id`=7,`name`=\"tom\" WHERE `member`.`tenant_id` = 7 AND `id` = 1"; err != nil || stmt.Interpolated != expected {
+		t.Errorf("Save with tenant expects %s got %s, %v", expected, stmt.Interpolated, err)
+	}
+	stmt, err = member.WithTenant(7).ToSQL(func(tx Dao) error { return tx.Save(&MemberRaw{Name: "tom"}) })
+	if expected := "INSERT INTO `member` (`tenant_id`,`name`) VALUES (7,\"tom\")"; err != nil || stmt.Interpolated != expected {
+		t.Errorf("Save new record with tenant expects %s got %s, %v", expected, stmt.Interpolated, err)
+	}
+}
+
+type testConnPool struct {
+	gorm.ConnPool
+	name string
+}
+
+type testTxPool struct {
+	gorm.ConnPool
+	gorm.TxCommitter
+}
+
+func TestDO_replicas(t *testing.T) {
+	replica := func(name string) *gorm.DB {
+		return &gorm.DB{Statement: &gorm.Statement{ConnPool: &testConnPool{name: name}}}
+	}
+	poolName := func(db *gorm.DB) string {
+		if pool, ok := db.Statement.ConnPool.(*testConnPool); ok {
+			return pool.name
+		}
+		return "primary"
+	}
+
+	d := u.getInstance(WithReplicas(u.db, replica("r1"), replica("r2")))
+	for _, expected := range []string{"r1", "r2", "r1"} {
+		if name := poolName(d.readDB()); name != expected {
+			t.Errorf("read finisher expects routed to %s got %s", expected, name)
+		}
+	}
+	if name := poolName(d.db); name != "primary" {
+		t.Errorf("primary db should not be affected by replicas got %s", name)
+	}
+	if name := poolName(d.UsePrimary().(*DO).readDB()); name != "primary" {
+		t.Errorf("UsePrimary expects routed to primary got %s", name)
+	}
+	if name := poolName(d.ForUpdate().(*DO).readDB()); name != "primary" {
+		t.Errorf("locking read expects routed to primary got %s", name)
+	}
+
+	tx := d.db.Session(&gorm.Session{Context: context.Background()})
+	tx.Statement.ConnPool = &testTxPool{}
+	if pool := d.getInstance(tx).readDB().Statement.ConnPool; pool != tx.Statement.ConnPool {
+		t.Errorf("read finisher in transaction expects routed to primary got %+v", pool)
+	}
+
+	if db := u.readDB(); db != u.db {
+		t.Errorf("read finisher without replicas expects routed to primary")
+	}
+
+	// generated Query.UsePrimary clone query structs by ReplaceDB, table of model is kept in subqueries
+	primary := employee.DO
+	primary.ReplaceDB(UsePrimary(WithReplicas(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}), replica("r1"))))
+	if name := poolName(primary.readDB()); name != "primary" {
+		t.Errorf("cloned DO with UsePrimary expects routed to primary got %s", name)
+	}
+	reports := primary.Select(employee.ManagerID).Group(employee.ManagerID).As("r")
+	for _, tc := range []struct {
+		Expr   Dao
+		Result string
+	}{
+		{
+			Expr:   primary.Select(employee.ID).UnionAll(primary.Select(employee.ID)),
+			Result: "SELECT * FROM (SELECT `employee`.`id` FROM `employee` WHERE `employee`.`deleted_at` IS NULL UNION ALL SELECT `employee`.`id` FROM `employee` WHERE `employee`.`deleted_at` IS NULL) AS `employee`",
+		},
+		{
+			Expr:   With("managers", primary.Select(employee.ID).Where(employee.ManagerID.Eq(0))),
+			Result: "WITH `managers` AS (SELECT `employee`.`id` FROM `employee` WHERE `employee`.`manager_id` = 0 AND `employee`.`deleted_at` IS NULL) SELECT * FROM `managers`",
+		},
+		{
+			Expr:   primary.Select(employee.Name).Join(reports, field.NewInt64("r", "manager_id").EqCol(employee.ID)),
+			Result: "SELECT `employee`.`name` FROM `employee` INNER JOIN (SELECT `employee`.`manager_id` FROM `employee` WHERE `employee`.`deleted_at` IS NULL GROUP BY `employee`.`manager_id`) AS `r` ON `r`.`manager_id` = `employee`.`id` WHERE `employee`.`deleted_at` IS NULL",
+		},
+	} {
+		stmt, err := tc.Expr.ToSQL(func(tx Dao) error { _, err := tx.Find(); return err })
+		if err != nil || stmt.Interpolated != tc.Result {
+			t.Errorf("query of DO using primary expects %s got %s, %v", tc.Result, stmt.Interpolated, err)
+		}
+	}
+}
+
+func TestDO_sharding(t *testing.T) {
+	stmtOf := func(d Dao, exec func(db *gorm.DB) *gorm.DB) *gorm.Statement {
+		return exec(d.(*DO).db).Statement
+	}
+	find := func(db *gorm.DB) *gorm.DB { return db.Find(&[]OrderRaw{}) }
+
+	if order.sharding == nil || order.sharding.table != "orders" {
+		t.Fatalf("parse sharding of model fail: %+v", order.sharding)
+	}
+	if _, err := order.Find(); !errors.Is(err, ErrNoShardingKey) {
+		t.Errorf("Find without sharding key expects %v got %v", ErrNoShardingKey, err)
+	}
+	if _, err := order.Where(order.UserID.In(1, 2)).Find(); !errors.Is(err, ErrCrossShard) {
+		t.Errorf("Find in multiple shards expects %v got %v", ErrCrossShard, err)
+	}
+	if _, err := u.FanOut().Find(); !errors.Is(err, ErrNoSharding) {
+		t.Errorf("FanOut on model without sharding expects %v got %v", ErrNoSharding, err)
+	}
+
+	stmt := stmtOf(order.Where(order.UserID.Eq(5), order.Amount.Gt(1)), find)
+	if expected := "SELECT * FROM `orders_01` AS `orders` WHERE `orders`.`user_id` = ? AND `orders`.`amount` > ?"; stmt.Error != nil || stmt.SQL.String() != expected {
+		t.Errorf("SQL expects %q got %q, %v", expected, stmt.SQL.String(), stmt.Error)
+	}
+	stmt = stmtOf(order.Where(order.UserID.In(1, 5)), func(db *gorm.DB) *gorm.DB { return db.Delete(&OrderRaw{}) })
+	if expected := "DELETE FROM `orders_01` AS `orders` WHERE `orders`.`user_id` IN (?,?)"; stmt.Error != nil || stmt.SQL.String() != expected {
+		t.Errorf("SQL expects %q got %q, %v", expected, stmt.SQL.String(), stmt.Error)
+	}
+	stmt = stmtOf(&order.DO, func(db *gorm.DB) *gorm.DB { return db.Updates(&OrderRaw{ID: 1, UserID: 6, Amount: 2}) })
+	if expected := "UPDATE `orders_02` AS `orders` SET `user_id`=?,`amount`=? WHERE `id` = ?"; stmt.Error != nil || stmt.SQL.String() != expected {
+		t.Errorf("SQL expects %q got %q, %v", expected, stmt.SQL.String(), stmt.Error)
+	}
+
+	var inserts []string
+	create := func(tx *gorm.DB, value interface{}) error {
+		stmt := tx.Create(value).Statement
+		inserts = append(inserts, stmt.SQL.String())
+		return stmt.Error
+	}
+	if err := order.shardCreate(order.db, []*OrderRaw{{UserID: 3}, {UserID: 7}}, create); err != nil {
+		t.Errorf("create in shard expects no error got %v", err)
+	}
+	if expected := []string{"INSERT INTO `orders_03` (`user_id`,`amount`) VALUES (?,?),(?,?)"}; !reflect.DeepEqual(inserts, expected) {
+		t.Errorf("SQL expects %q got %q", expected, inserts)
+	}
+	if err := order.Create(&OrderRaw{Amount: 1}); !errors.Is(err, ErrNoShardingKey) {
+		t.Errorf("Create without sharding key expects %v got %v", ErrNoShardingKey, err)
+	}
+
+	if _, err := order.FanOut().Where(order.Amount.Gt(1)).Find(); err != nil {
+		t.Errorf("FanOut Find expects no error got %v", err)
+	}
+	if _, err := order.FanOut().Count(); err != nil {
+		t.Errorf("FanOut Count expects no error got %v", err)
+	}
+}
+
+func TestDO_fanOut(t *testing.T) {
+	// every shard returns the same unsorted rows
+	var sqls []string
+	sqlDB := sql.OpenDB(testRowsConnector{
+		columns: []string{"id", "user_id", "amount"},
+		rows:    [][]driver.Value{{int64(3), int64(1), 30.0}, {int64(1), int64(2), 10.0}, {int64(2), int64(3), 20.0}},
+		sqls:    &sqls,
+	})
+	defer sqlDB.Close()
+
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+	d := order.getInstance(conn)
+	d.UseModel(OrderRaw{})
+
+	results, err := d.FanOut().Order(order.Amount.Desc(), order.ID).Offset(1).Limit(3).Find()
+	if err != nil {
+		t.Fatalf("FanOut Find fail: %v", err)
+	}
+	var amounts []float64
+	for _, o := range results.([]*OrderRaw) {
+		amounts = append(amounts, o.Amount)
+	}
+	if expected := []float64{30, 30, 30}; !reflect.DeepEqual(amounts, expected) {
+		t.Errorf("FanOut Find expects merged page %v got %v", expected, amounts)
+	}
+	if len(sqls) != 4 || !strings.HasSuffix(sqls[0], "ORDER BY `orders`.`amount` DESC,`orders`.`id` LIMIT 4") {
+		t.Errorf("FanOut Find expects first offset+limit rows of each shard got %q", sqls)
+	}
+
+	results, err = d.FanOut().Order(order.Amount).Offset(10).Find()
+	if err != nil || len(results.([]*OrderRaw)) != 2 || results.([]*OrderRaw)[0].Amount != 30 {
+		t.Errorf("FanOut Find with offset expects the last 2 rows got %+v, %v", results, err)
+	}
+
+	if _, err := d.FanOut().Order(order.Amount.Add(1)).Find(); err == nil {
+		t.Errorf("FanOut Find ordered by expression expects error")
+	}
+}
+
+func TestDO_upsert(t *testing.T) {
+	mysqlDB := student.db.Session(new(gorm.Session))
+	mysqlDB.Config.ClauseBuilders = mysql.Dialector{Config: &mysql.Config{}}.ClauseBuilders()
+	mysqlStudent := student.DO
+	mysqlStudent.ReplaceDB(mysqlDB)
+	postgresStudent := student.DO
+	postgresStudent.ReplaceDB(postgresDB)
+	aliasedStudent := postgresStudent
+	aliasedStudent.UseAlias("s")
+
+	testcases := []struct {
+		Expr         Dao
+		ExpectedVars []interface{}
+		Result       string
+	}{
+		{
+			Expr:         student.OnConflict(student.ID).DoUpdate(student.Name, student.Age.Add(1), student.Instructor.Value(2)),
+			ExpectedVars: []interface{}{"tom", 18, int64(0), int64(1), 1, int64(2)},
+			Result:       "INSERT INTO `student` (`name`,`age`,`instructor`,`id`) VALUES (?,?,?,?) ON CONFLICT (`id`) DO UPDATE SET `name`=`excluded`.`name`,`age`=`age`+?,`instructor`=?",
+		},
+		{
+			Expr:         student.OnConflict(student.ID).DoNothing(),
+			ExpectedVars: []interface{}{"tom", 18, int64(0), int64(1)},
+			Result:       "INSERT INTO `student` (`name`,`age`,`instructor`,`id`) VALUES (?,?,?,?) ON CONFLICT (`id`) DO NOTHING",
+		},
+		{
+			// PostgreSQL rejects unqualified column of the conflicted row as ambiguous
+			Expr:         postgresStudent.OnConflict(student.ID).DoUpdate(student.Name, student.Age.Add(1), student.Instructor.Value(2)),
+			ExpectedVars: []interface{}{"tom", 18, int64(0), int64(1), 1, int64(2)},
+			Result:       "INSERT INTO `student` (`name`,`age`,`instructor`,`id`) VALUES (?,?,?,?) ON CONFLICT (`id`) DO UPDATE SET `name`=`excluded`.`name`,`age`=`student`.`age`+?,`instructor`=?",
+		},
+		{
+			Expr:         aliasedStudent.OnConflict(student.ID).DoUpdate(student.Age.Add(1)),
+			ExpectedVars: []interface{}{"tom", 18, int64(0), int64(1), 1},
+			Result:       "INSERT INTO `student` AS `s` (`name`,`age`,`instructor`,`id`) VALUES (?,?,?,?) ON CONFLICT (`id`) DO UPDATE SET `age`=`s`.`age`+?",
+		},
+		{
+			Expr:         mysqlStudent.OnConflict(student.ID).DoUpdate(student.Name, student.Age.Add(1), student.Instructor.Value(2)),
+			ExpectedVars: []interface{}{"tom", 18, int64(0), int64(1), 1, int64(2)},
+			Result:       "INSERT INTO `student` (`name`,`age`,`instructor`,`id`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`),`age`=`age`+?,`instructor`=?",
+		},
+		{
+			Expr:         mysqlStudent.OnConflict(student.ID).DoNothing(),
+			ExpectedVars: []interface{}{"tom", 18, int64(0), int64(1)},
+			Result:       "INSERT INTO `student` (`name`,`age`,`instructor`,`id`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`",
+		},
+	}
+
+	for _, testcase := range testcases {
+		stmt := testcase.Expr.(*DO).db.Create(&StudentRaw{ID: 1, Name: "tom", Age: 18}).Statement
+		if stmt.Error != nil {
+			t.Errorf("upsert expects no error got %v", stmt.Error)
+		}
+		if sql := stmt.SQL.String(); sql != testcase.Result {
+			t.Errorf("SQL expects %q got %q", testcase.Result, sql)
+		}
+		if !reflect.DeepEqual(stmt.Vars, testcase.ExpectedVars) {
+			t.Errorf("Vars expects %+v got %+v", testcase.ExpectedVars, stmt.Vars)
+		}
+	}
+
+	if err := student.DoUpdate().Create(&StudentRaw{}); err == nil {
+		t.Errorf("DoUpdate without column expects error")
+	}
+	if err := student.DoUpdate(student.Name).Create(&StudentRaw{}); err == nil || !strings.Contains(err.Error(), "conflict target") {
+		t.Errorf("DoUpdate without conflict target expects error got %v", err)
+	}
+	if err := student.OnConflict(student.ID).Create(&StudentRaw{}); err == nil || !strings.Contains(err.Error(), "OnConflict") {
+		t.Errorf("OnConflict without DoUpdate or DoNothing expects error got %v", err)
+	}
+	if err := student.DoNothing().Create(&StudentRaw{}); err != nil {
+		t.Errorf("DoNothing without conflict target expects no error got %v", err)
+	}
+	// conflict target is ignored by MySQL
+	if err := mysqlStudent.DoUpdate(student.Name).Create(&StudentRaw{}); err != nil {
+		t.Errorf("DoUpdate without conflict target in MySQL expects no error got %v", err)
+	}
+}
+
+func TestDO_cursor(t *testing.T) {
+	publishedAt := time.Date(2021, 10, 18, 0, 0, 0, 0, time.UTC)
+	record := &PostRaw{ID: 7, Score: 3, PublishedAt: &publishedAt}
+
+	testcases := []struct {
+		Columns      []field.Expr
+		Record       *PostRaw
+		ExpectedVars []interface{}
+		Result       string
+	}{
+		{
+			Columns:      []field.Expr{post.Score.Desc(), post.ID.Desc()},
+			Record:       record,
+			ExpectedVars: []interface{}{3, int64(7)},
+			Result:       "WHERE (`post`.`score`,`post`.`id`) < (?,?)",
+		},
+		{
+			Columns:      []field.Expr{post.Score.Desc(), post.ID},
+			Record:       record,
+			ExpectedVars: []interface{}{3, 3, int64(7)},
+			Result:       "WHERE (`post`.`score` < ? OR (`post`.`score` = ? AND `post`.`id` > ?))",
+		},
+		{
+			Columns:      []field.Expr{post.PublishedAt, post.ID},
+			Record:       record,
+			ExpectedVars: []interface{}{publishedAt, publishedAt, int64(7)},
+			Result:       "WHERE ((`post`.`published_at` > ? OR `post`.`published_at` IS NULL) OR (`post`.`published_at` = ? AND `post`.`id` > ?))",
+		},
+		{
+			Columns:      []field.Expr{post.PublishedAt, post.ID},
+			Record:       &PostRaw{ID: 7},
+			ExpectedVars: []interface{}{int64(7)},
+			Result:       "WHERE (`post`.`published_at` IS NULL AND `post`.`id` > ?)",
+		},
+		{
+			Columns:      []field.Expr{post.PublishedAt.Desc(), post.ID.Desc()},
+			Record:       &PostRaw{ID: 7},
+			ExpectedVars: []interface{}{int64(7)},
+			Result:       "WHERE (`post`.`published_at` IS NOT NULL OR (`post`.`published_at` IS NULL AND `post`.`id` < ?))",
+		},
+	}
+
+	for _, testcase := range testcases {
+		keys, err := post.cursorKeys(testcase.Columns)
+		if err != nil {
+			t.Fatalf("parse cursor keys fail: %v", err)
+		}
+		cursor, err := encodeCursor(keys, reflect.ValueOf(testcase.Record), false)
+		if err != nil {
+			t.Fatalf("encode cursor fail: %v", err)
+		}
+		values, backward, err := decodeCursor(keys, cursor)
+		if err != nil || backward {
+			t.Fatalf("decode cursor fail: %v", err)
+		}
+		for i, value := range values {
+			if v, ok := value.(*time.Time); ok && v != nil {
+				values[i] = *v
+			}
+		}
+		checkBuildExpr(t, post.getInstance(post.db.Where(post.afterCursor(keys, values))), nil, testcase.Result, testcase.ExpectedVars)
+	}
+
+	if _, _, err := post.FindByCursor("invalid", 10, post.ID); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("FindByCursor with invalid cursor expects %v got %v", ErrInvalidCursor, err)
+	}
+	if _, _, err := post.FindByCursor("", 10, post.Score.Add(1)); err == nil {
+		t.Errorf("FindByCursor with expression column expects error")
+	}
+	if _, page, err := post.FindByCursor("", 10, post.Score.Desc(), post.ID.Desc()); err != nil || page != (CursorPage{}) {
+		t.Errorf("FindByCursor first page expects no error and no cursors got %+v, %v", page, err)
+	}
+}
+
+// testRowsConnector connector of database returns fixed rows for any query
+type testRowsConnector struct {
+	columns []string
+	rows    [][]driver.Value
+	queries *int      // count of queries
+	sqls    *[]string // statements of queries and execs
+	begins  *int      // count of transactions
+}
+
+func (c testRowsConnector) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c testRowsConnector) Driver() driver.Driver                        { return nil }
+func (c testRowsConnector) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (c testRowsConnector) Close() error                                 { return nil }
+func (c testRowsConnector) Commit() error                                { return nil }
+func (c testRowsConnector) Rollback() error                              { return nil }
+
+func (c testRowsConnector) Begin() (driver.Tx, error) {
+	if c.begins != nil {
+		*c.begins++
+	}
+	return c, nil
+}
+
+func (c testRowsConnector) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if c.queries != nil {
+		*c.queries++
+	}
+	if c.sqls != nil {
+		*c.sqls = append(*c.sqls, query)
+	}
+	return &testRows{testRowsConnector: c}, nil
+}
+
+func (c testRowsConnector) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if c.sqls != nil {
+		*c.sqls = append(*c.sqls, query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type testRows struct {
+	testRowsConnector
+	next int
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestDO_iter(t *testing.T) {
+	sqlDB := sql.OpenDB(testRowsConnector{
+		columns: []string{"id", "name", "age"},
+		rows:    [][]driver.Value{{int64(1), "alice", int64(18)}, {int64(2), "bob", int64(20)}},
+	})
+	defer sqlDB.Close()
+
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+	d := u.getInstance(conn)
+	d.UseModel(User{})
+
+	it, err := d.Where(u.Age.Gt(10)).Iter()
+	if err != nil {
+		t.Fatalf("Iter fail: %v", err)
+	}
+	defer it.Close()
+
+	var users []*User
+	for it.Next() {
+		user, ok := it.Value().(*User)
+		if !ok {
+			t.Fatalf("Iter value expects *User got %T", it.Value())
+		}
+		users = append(users, user)
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("Iter expects no error got %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "alice" || users[1].Age != 20 || users[0] == users[1] {
+		t.Errorf("Iter expects 2 distinct users got %+v", users)
+	}
+	if it.Value() != nil {
+		t.Errorf("Iter value after the last row expects nil got %+v", it.Value())
+	}
+
+	if _, err := u.Iter(); !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Errorf("Iter in dry run mode expects %v got %v", gorm.ErrDryRunModeUnsupported, err)
+	}
+}
+
+func TestDO_cache(t *testing.T) {
+	var queries int
+	sqlDB := sql.OpenDB(testRowsConnector{
+		columns: []string{"id", "name", "age"},
+		rows:    [][]driver.Value{{int64(1), "alice", int64(18)}, {int64(2), "bob", int64(20)}},
+		queries: &queries,
+	})
+	defer sqlDB.Close()
+
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+	d := u.getInstance(WithCache(conn, NewMemoryCache()))
+	d.UseModel(User{})
+
+	find := func(do Dao) []*User {
+		results, err := do.Where(u.Age.Gt(10)).Find()
+		if err != nil {
+			t.Fatalf("Find fail: %v", err)
+		}
+		return results.([]*User)
+	}
+
+	first := find(d.Cache(time.Minute))
+	cached := find(d.Cache(time.Minute))
+	if queries != 1 {
+		t.Errorf("cached Find expects 1 query got %d", queries)
+	}
+	if !reflect.DeepEqual(first, cached) {
+		t.Errorf("cached Find expects %+v got %+v", first, cached)
+	}
+
+	find(d.Cache(time.Minute).Where(u.Name.Eq("alice")))
+	find(d)
+	if queries != 3 {
+		t.Errorf("Find with other conditions or without Cache expects query, queries: %d", queries)
+	}
+
+	if _, err := d.Where(u.ID.Eq(1)).UpdateSimple(u.Age.Add(1)); err != nil {
+		t.Fatalf("UpdateSimple fail: %v", err)
+	}
+	find(d.Cache(time.Minute))
+	if queries != 4 {
+		t.Errorf("Find after update expects query, queries: %d", queries)
+	}
+
+	if err := u.Cache(time.Minute).(*DO).db.Error; !errors.Is(err, ErrNoCache) {
+		t.Errorf("Cache without WithCache expects %v got %v", ErrNoCache, err)
+	}
+}
+
+func TestDO_cachePointerZero(t *testing.T) {
+	type ScoreRaw struct {
+		ID     int64
+		Score  *int
+		Nick   *string
+		Secret string `json:"-"`
+	}
+	type PublicScoreRaw struct {
+		ID    int64
+		Score *int
+		Nick  *string
+	}
+
+	var queries int
+	sqlDB := sql.OpenDB(testRowsConnector{
+		columns: []string{"id", "score", "nick", "secret"},
+		rows:    [][]driver.Value{{int64(1), int64(0), "", "s"}, {int64(2), nil, nil, "s"}},
+		queries: &queries,
+	})
+	defer sqlDB.Close()
+
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+
+	var d DO
+	d.UseDB(WithCache(conn, NewMemoryCache()))
+	d.UseModel(PublicScoreRaw{})
+	for i := 0; i < 2; i++ {
+		results, err := d.Cache(time.Minute).Find()
+		if err != nil {
+			t.Fatalf("Find fail: %v", err)
+		}
+		scores := results.([]*PublicScoreRaw)
+		if len(scores) != 2 || scores[0].Score == nil || *scores[0].Score != 0 || scores[0].Nick == nil || *scores[0].Nick != "" {
+			t.Errorf("Find expects zero values referenced by pointers kept got %+v", scores)
+		}
+		if scores[1].Score != nil || scores[1].Nick != nil {
+			t.Errorf("Find expects NULL kept got %+v", scores[1])
+		}
+	}
+	if queries != 1 {
+		t.Errorf("cached Find expects 1 query got %d", queries)
+	}
+
+	// fields ignored by json would be lost in cache
+	d.UseModel(ScoreRaw{})
+	for i := 0; i < 2; i++ {
+		results, err := d.Cache(time.Minute).Find()
+		if err != nil || results.([]*ScoreRaw)[0].Secret != "s" {
+			t.Errorf("Find of model with json ignored field expects secret got %+v, %v", results, err)
+		}
+	}
+	if queries != 3 {
+		t.Errorf("Find of model with json ignored field expects not cached, queries: %d", queries)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache()
+
+	_ = cache.Set(ctx, "expired", []byte("v"), time.Nanosecond)
+	_ = cache.Set(ctx, "forever", []byte("v"), 0)
+	time.Sleep(time.Millisecond)
+
+	if _, ok, _ := cache.Get(ctx, "expired"); ok {
+		t.Errorf("expired entry expects missing")
+	}
+	if value, ok, _ := cache.Get(ctx, "forever"); !ok || string(value) != "v" {
+		t.Errorf("entry without ttl expects v got %q", value)
+	}
+	_ = cache.Delete(ctx, "forever")
+	if _, ok, _ := cache.Get(ctx, "forever"); ok {
+		t.Errorf("deleted entry expects missing")
+	}
+}
+
+func TestDO_interceptors(t *testing.T) {
+	var queries int
+	sqlDB := sql.OpenDB(testRowsConnector{
+		columns: []string{"id", "name", "age"},
+		rows:    [][]driver.Value{{int64(1), "alice", int64(18)}, {int64(2), "bob", int64(20)}},
+		queries: &queries,
+	})
+	defer sqlDB.Close()
+
+	var calls []string
+	var last Invocation
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, inv *Invocation, next func() error) error {
+			calls = append(calls, name+":"+inv.Finisher)
+			err := next()
+			last = *inv
+			return err
+		}
+	}
+	denied := errors.New("denied")
+	var seen []string
+	deny := func(ctx context.Context, inv *Invocation, next func() error) error {
+		seen = append(seen, inv.SQL)
+		if inv.Finisher == "ForceDelete" {
+			return denied
+		}
+		return next()
+	}
+
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+	plain := u.getInstance(WithInterceptors(conn, record("query")))
+	plain.UseModel(User{})
+	d := plain.UseInterceptors(record("model"), deny).(*DO)
+	if len(plain.interceptors) != 0 {
+		t.Errorf("UseInterceptors expects DO not changed got %d interceptors", len(plain.interceptors))
+	}
+
+	results, err := d.Where(u.Age.Gt(10)).Find()
+	if err != nil || len(results.([]*User)) != 2 {
+		t.Fatalf("intercepted Find expects 2 users got %+v, %v", results, err)
+	}
+	if expected := []string{"query:Find", "model:Find"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("interceptors expects called in order %v got %v", expected, calls)
+	}
+	if last.Table != "users_info" || last.SQL != "SELECT * FROM `users_info` WHERE `age` > 10" || last.RowsAffected != 2 || last.Duration <= 0 {
+		t.Errorf("invocation expects traced statement got %+v", last)
+	}
+
+	calls = nil
+	if _, err := d.Where(u.ID.Eq(1)).ForceDelete(); !errors.Is(err, denied) {
+		t.Errorf("short-circuited ForceDelete expects %v got %v", denied, err)
+	}
+	if _, err := d.Where(u.ID.Eq(1)).Delete(); err != nil {
+		t.Errorf("intercepted Delete expects no error got %v", err)
+	}
+	if expected := []string{"query:ForceDelete", "model:ForceDelete", "query:Delete", "model:Delete"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("interceptors expects called %v got %v", expected, calls)
+	}
+	if queries != 1 {
+		t.Errorf("short-circuited finisher expects no query, queries: %d", queries)
+	}
+	if expected := []string{"SELECT * FROM `users_info` WHERE `age` > 10", "DELETE FROM `users_info` WHERE `id` = 1", "DELETE FROM `users_info` WHERE `id` = 1"}; !reflect.DeepEqual(seen, expected) {
+		t.Errorf("interceptors expects rendered sql before next %v got %v", expected, seen)
+	}
+
+	calls = nil
+	if row := d.Where(u.ID.Eq(1)).Row(); row == nil {
+		t.Errorf("intercepted Row expects row got nil")
+	}
+	if expected := []string{"query:Row", "model:Row"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("interceptors expects called %v got %v", expected, calls)
+	}
+
+	// rows of the caller are scanned once, they are not consumed by rendering
+	calls = nil
+	rows, err := conn.Model(User{}).Rows()
+	if err != nil || !rows.Next() {
+		t.Fatalf("Rows expects rows got %v", err)
+	}
+	var users []User
+	if err := d.ScanRows(rows, &users); err != nil || len(users) != 2 {
+		t.Errorf("intercepted ScanRows expects 2 users got %+v, %v", users, err)
+	}
+	_ = rows.Close()
+	if expected := []string{"query:ScanRows", "model:ScanRows"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("interceptors expects called %v got %v", expected, calls)
+	}
+
+	// results of short-circuited finishers are not written by rendering
+	model := u.getInstance(conn)
+	model.UseModel(User{})
+	skipped := model.UseInterceptors(func(ctx context.Context, inv *Invocation, next func() error) error { return nil })
+	if result, err := skipped.Where(u.ID.Eq(1)).First(); result != nil || err != nil {
+		t.Errorf("short-circuited First expects nil result got %+v, %v", result, err)
+	}
+	if info, err := skipped.Where(u.ID.Eq(1)).Update(u.Age, 1); info.RowsAffected != 0 || err != nil {
+		t.Errorf("short-circuited Update expects no rows affected got %+v, %v", info, err)
+	}
+	if ids, err := skipped.Where(u.ID.Eq(1)).PluckUint(u.ID); len(ids) != 0 || err != nil {
+		t.Errorf("short-circuited PluckUint expects no values got %+v, %v", ids, err)
+	}
+
+	calls = nil
+	err = conn.Transaction(func(tx *gorm.DB) error {
+		// generated Query.Transaction replace db of DO with transaction by clone
+		txDO := *d
+		txDO.ReplaceDB(tx)
+		_, err := txDO.Find()
+		return err
+	})
+	if err != nil {
+		t.Errorf("Find in transaction expects no error got %v", err)
+	}
+	if expected := []string{"model:Find"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("model interceptors expects called in transaction %v got %v", expected, calls)
+	}
+}
+
+func TestDO_ToSQL(t *testing.T) {
+	testcases := []struct {
+		Finisher     func(tx Dao) error
+		ExpectedSQL  string
+		ExpectedVars []interface{}
+		Interpolated string
+	}{
+		{
+			Finisher:     func(tx Dao) error { _, err := tx.Where(u.Age.Gt(18)).Order(u.ID).Limit(10).Find(); return err },
+			ExpectedSQL:  "SELECT * FROM `users_info` WHERE `age` > ? ORDER BY `id` LIMIT 10",
+			ExpectedVars: []interface{}{18},
+			Interpolated: "SELECT * FROM `users_info` WHERE `age` > 18 ORDER BY `id` LIMIT 10",
+		},
+		{
+			Finisher:     func(tx Dao) error { _, err := tx.Where(u.Name.Eq("modi")).First(); return err },
+			ExpectedSQL:  "SELECT * FROM `users_info` WHERE `name` = ? ORDER BY `users_info`.`id` LIMIT 1",
+			ExpectedVars: []interface{}{"modi"},
+		},
+		{
+			Finisher:    func(tx Dao) error { _, err := tx.Count(); return err },
+			ExpectedSQL: "SELECT count(*) FROM `users_info`",
+		},
+		{
+			Finisher:     func(tx Dao) error { _, err := tx.Where(u.ID.Eq(1)).Update(u.Name, "modi"); return err },
+			ExpectedSQL:  "UPDATE `users_info` SET `name`=? WHERE `id` = ?",
+			ExpectedVars: []interface{}{"modi", uint(1)},
+		},
+		{
+			Finisher:     func(tx Dao) error { _, err := tx.Where(u.ID.Eq(1)).UpdateSimple(u.Age.Add(1)); return err },
+			ExpectedSQL:  "UPDATE `users_info` SET `age`=`age`+? WHERE `id` = ?",
+			ExpectedVars: []interface{}{1, uint(1)},
+		},
+		{
+			Finisher:     func(tx Dao) error { _, err := tx.Where(u.ID.Eq(1)).Delete(); return err },
+			ExpectedSQL:  "DELETE FROM `users_info` WHERE `id` = ?",
+			ExpectedVars: []interface{}{uint(1)},
+		},
+		{
+			Finisher:     func(tx Dao) error { return tx.Create(&User{Name: "modi"}) },
+			ExpectedSQL:  "INSERT INTO `users_info` (`name`,`age`,`score`,`address`,`famous`,`register_at`) VALUES (?,?,?,?,?,?)",
+			ExpectedVars: []interface{}{"modi", 0, 0.0, "", false, time.Time{}},
+		},
+	}
+
+	for _, testcase := range testcases {
+		stmt, err := u.ToSQL(testcase.Finisher)
+		if err != nil {
+			t.Fatalf("ToSQL fail: %v", err)
+		}
+		if stmt.SQL != testcase.ExpectedSQL {
+			t.Errorf("SQL expects %q got %q", testcase.ExpectedSQL, stmt.SQL)
+		}
+		if len(stmt.Vars) != len(testcase.ExpectedVars) || len(stmt.Vars) > 0 && !reflect.DeepEqual(stmt.Vars, testcase.ExpectedVars) {
+			t.Errorf("Vars expects %+v got %+v", testcase.ExpectedVars, stmt.Vars)
+		}
+		if testcase.Interpolated != "" && stmt.String() != testcase.Interpolated {
+			t.Errorf("interpolated SQL expects %q got %q", testcase.Interpolated, stmt.String())
+		}
+	}
+
+	if _, err := u.ToSQL(func(tx Dao) error { _, err := tx.Delete(); return err }); !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("ToSQL of Delete without condition expects %v got %v", gorm.ErrMissingWhereClause, err)
+	}
+	if _, err := member.ToSQL(func(tx Dao) error { _, err := tx.Find(); return err }); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("ToSQL without tenant expects %v got %v", ErrNoTenant, err)
+	}
+	if _, err := u.ToSQL(func(tx Dao) error { return nil }); !errors.Is(err, ErrNoStatement) {
+		t.Errorf("ToSQL without finisher expects %v got %v", ErrNoStatement, err)
+	}
+
+	// finishers running several statements in transaction don't begin transaction in dry run
+	var begins int
+	sqlDB := sql.OpenDB(testRowsConnector{begins: &begins})
+	defer sqlDB.Close()
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+	doOf := func(d *DO, model interface{}) *DO {
+		d = d.getInstance(conn)
+		d.UseModel(model)
+		return d
+	}
+	tenantDO := doOf(&member.DO, MemberRaw{})
+	tenantDO.UseTenant("tenant_id")
+	for name, finisher := range map[string]func() (SQLStatement, error){
+		"versioned Save": func() (SQLStatement, error) {
+			return doOf(&account.DO, AccountRaw{}).ToSQL(func(tx Dao) error {
+				return tx.Save([]*AccountRaw{{ID: 1, Name: "tom", Version: 1}, {ID: 2, Name: "modi", Version: 1}})
+			})
+		},
+		"UpdateBatch": func() (SQLStatement, error) {
+			return doOf(&u.DO, User{}).ToSQL(func(tx Dao) error {
+				_, err := tx.UpdateBatch([]*User{{ID: 1, Name: "tom"}, {ID: 2, Name: "modi"}}, 1, u.Name)
+				return err
+			})
+		},
+		"tenant Save": func() (SQLStatement, error) {
+			return tenantDO.WithTenant(7).ToSQL(func(tx Dao) error {
+				return tx.Save([]*MemberRaw{{ID: 1, Name: "tom"}, {Name: "modi"}})
+			})
+		},
+		"sharded Create": func() (SQLStatement, error) {
+			return doOf(&order.DO, OrderRaw{}).ToSQL(func(tx Dao) error {
+				return tx.Create([]*OrderRaw{{UserID: 1, Amount: 1}, {UserID: 2, Amount: 2}})
+			})
+		},
+	} {
+		if stmt, err := finisher(); err != nil || stmt.SQL == "" {
+			t.Errorf("ToSQL of %s expects statement got %+v, %v", name, stmt, err)
+		}
+	}
+	if begins != 0 {
+		t.Errorf("ToSQL expects no transaction begun got %d", begins)
+	}
+}
+
+func TestDO_explain(t *testing.T) {
+	explain := func(columns []string, rows ...[]driver.Value) (*ExplainPlan, error) {
+		sqlDB := sql.OpenDB(testRowsConnector{columns: columns, rows: rows})
+		defer sqlDB.Close()
+
+		conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+		callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+		d := u.getInstance(conn)
+		d.UseModel(User{})
+		return d.Where(u.Age.Gt(18)).Explain()
+	}
+
+	testcases := []struct {
+		Columns  []string
+		Rows     [][]driver.Value
+		Expected []ExplainStep
+	}{
+		{ // mysql json
+			Columns: []string{"EXPLAIN"},
+			Rows: [][]driver.Value{{`{"query_block": {"select_id": 1, "nested_loop": [
+				{"table": {"table_name": "users_info", "access_type": "ALL", "rows_examined_per_scan": 100, "filtered": "33.33"}},
+				{"table": {"table_name": "student", "access_type": "ref", "key": "idx_instructor", "rows_examined_per_scan": 2}}
+			]}}`}},
+			Expected: []ExplainStep{
+				{Table: "users_info", AccessType: "ALL", Rows: 100, FullScan: true},
+				{Table: "student", AccessType: "ref", Key: "idx_instructor", Rows: 2},
+			},
+		},
+		{ // mysql json format version 2
+			Columns: []string{"EXPLAIN"},
+			Rows: [][]driver.Value{{`{"operation": "Filter: (users_info.age > 18)", "inputs": [
+				{"operation": "Index range scan on users_info using idx_age", "access_type": "index", "table_name": "users_info", "index_name": "idx_age", "estimated_rows": 10, "actual_rows": 8}
+			]}`}},
+			Expected: []ExplainStep{{Table: "users_info", AccessType: "index", Key: "idx_age", Rows: 10, ActualRows: 8}},
+		},
+		{ // postgres json
+			Columns: []string{"QUERY PLAN"},
+			Rows: [][]driver.Value{{`[{"Plan": {"Node Type": "Hash Join", "Plans": [
+				{"Node Type": "Seq Scan", "Relation Name": "users_info", "Plan Rows": 1000},
+				{"Node Type": "Hash", "Plans": [{"Node Type": "Index Scan", "Relation Name": "student", "Index Name": "student_pkey", "Plan Rows": 1, "Actual Rows": 1}]}
+			]}}]`}},
+			Expected: []ExplainStep{
+				{Table: "users_info", AccessType: "Seq Scan", Rows: 1000, FullScan: true},
+				{Table: "student", AccessType: "Index Scan", Key: "student_pkey", Rows: 1, ActualRows: 1},
+			},
+		},
+		{ // mysql tabular
+			Columns:  []string{"id", "select_type", "table", "type", "possible_keys", "key", "rows", "Extra"},
+			Rows:     [][]driver.Value{{int64(1), "SIMPLE", "users_info", "range", "idx_age", "idx_age", int64(10), nil}},
+			Expected: []ExplainStep{{Table: "users_info", AccessType: "range", Key: "idx_age", Rows: 10}},
+		},
+	}
+
+	for _, testcase := range testcases {
+		plan, err := explain(testcase.Columns, testcase.Rows...)
+		if err != nil {
+			t.Fatalf("Explain fail: %v", err)
+		}
+		if !reflect.DeepEqual(plan.Steps, testcase.Expected) {
+			t.Errorf("Explain steps expects %+v got %+v", testcase.Expected, plan.Steps)
+		}
+		if fullScan := testcase.Expected[0].FullScan; plan.FullScan() != fullScan {
+			t.Errorf("Explain full scan expects %t got %t", fullScan, plan.FullScan())
+		}
+	}
+
+	if plan, _ := explain(testcases[0].Columns, testcases[0].Rows...); !plan.UsesIndex("idx_instructor") || plan.UsesIndex("idx_age") {
+		t.Errorf("Explain expects index idx_instructor used only got %+v", plan.Steps)
+	}
+	if _, err := u.Explain(); !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Errorf("Explain in dry run mode expects %v got %v", gorm.ErrDryRunModeUnsupported, err)
+	}
+}
+
+func TestDO_scalar(t *testing.T) {
+	open := func(columns []string, rows ...[]driver.Value) *DO {
+		sqlDB := sql.OpenDB(testRowsConnector{columns: columns, rows: rows})
+		t.Cleanup(func() { sqlDB.Close() })
+
+		conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+		callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+		d := u.getInstance(conn)
+		d.UseModel(User{})
+		return d
+	}
+
+	names, err := open([]string{"name"}, []driver.Value{"alice"}, []driver.Value{nil}).PluckString(u.Name)
+	if err != nil || !reflect.DeepEqual(names, []string{"alice", ""}) {
+		t.Errorf("PluckString expects [alice ] got %+v, %v", names, err)
+	}
+	ids, err := open([]string{"id"}).PluckUint(u.ID)
+	if err != nil || ids == nil || len(ids) != 0 {
+		t.Errorf("PluckUint without rows expects empty slice got %+v, %v", ids, err)
+	}
+	ids, err = open([]string{"id"}, []driver.Value{uint64(math.MaxUint64)}, []driver.Value{[]byte("9223372036854775808")}).PluckUint(u.ID)
+	if err != nil || !reflect.DeepEqual(ids, []uint{math.MaxUint64, 9223372036854775808}) {
+		t.Errorf("PluckUint expects values greater than MaxInt64 got %+v, %v", ids, err)
+	}
+	registerAt := time.Date(2021, 10, 18, 0, 0, 0, 0, time.UTC)
+	times, err := open([]string{"register_at"}, []driver.Value{registerAt}).PluckTime(u.RegisterAt)
+	if err != nil || len(times) != 1 || !times[0].Equal(registerAt) {
+		t.Errorf("PluckTime expects [%s] got %+v, %v", registerAt, times, err)
+	}
+
+	maxAge, err := open([]string{"max"}, []driver.Value{18.5}).ScanFloat64(u.Age.Max())
+	if err != nil || maxAge != 18.5 {
+		t.Errorf("ScanFloat64 expects 18.5 got %v, %v", maxAge, err)
+	}
+	count, err := open([]string{"count"}, []driver.Value{int64(3)}).ScanInt(u.ID.Count())
+	if err != nil || count != 3 {
+		t.Errorf("ScanInt expects 3 got %v, %v", count, err)
+	}
+	var minScore float64
+	if err := open([]string{"min"}, []driver.Value{60.5}).ScanOne(u.Score.Min(), &minScore); err != nil || minScore != 60.5 {
+		t.Errorf("ScanOne expects 60.5 got %v, %v", minScore, err)
+	}
+	if _, err := open([]string{"name"}).ScanString(u.Name); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("ScanString without rows expects %v got %v", gorm.ErrRecordNotFound, err)
+	}
+	if _, err := open([]string{"id", "name"}, []driver.Value{int64(1), "alice"}).PluckInt(u.Age); err == nil {
+		t.Errorf("PluckInt of several columns expects error")
+	}
+
+	stmt, err := u.ToSQL(func(tx Dao) error { _, err := tx.Where(u.Age.Gt(18)).ScanFloat64(u.Age.Max()); return err })
+	if err != nil || stmt.Interpolated != "SELECT MAX(`age`) FROM `users_info` WHERE `age` > 18" {
+		t.Errorf("ScanFloat64 expects select column got %q, %v", stmt.Interpolated, err)
+	}
+}
+
+func TestDO_UpdateBatch(t *testing.T) {
+	users := []*User{{ID: 1, Name: "alice", Age: 18}, {ID: 2, Name: "bob", Age: 20}}
+	stmt, err := u.ToSQL(func(tx Dao) error { _, err := tx.UpdateBatch(users, 0, u.Name, u.Age); return err })
+	if err != nil {
+		t.Fatalf("UpdateBatch fail: %v", err)
+	}
+	expectedSQL := "UPDATE `users_info` SET `age`=CASE `id` WHEN ? THEN ? WHEN ? THEN ? ELSE `age` END,`name`=CASE `id` WHEN ? THEN ? WHEN ? THEN ? ELSE `name` END WHERE `users_info`.`id` IN (?,?)"
+	if stmt.SQL != expectedSQL {
+		t.Errorf("SQL expects %q got %q", expectedSQL, stmt.SQL)
+	}
+	if expectedVars := []interface{}{uint(1), 18, uint(2), 20, uint(1), "alice", uint(2), "bob", uint(1), uint(2)}; !reflect.DeepEqual(stmt.Vars, expectedVars) {
+		t.Errorf("Vars expects %+v got %+v", expectedVars, stmt.Vars)
+	}
+
+	enroll := u.getInstance(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}))
+	enroll.UseModel(enrollRaw{})
+	enrolls := []*enrollRaw{{StudentID: 1, CourseID: 2, Score: 90}, {StudentID: 1, CourseID: 3, Score: 80}}
+	stmt, err = enroll.ToSQL(func(tx Dao) error { _, err := tx.UpdateBatch(enrolls, 0); return err })
+	if err != nil {
+		t.Fatalf("UpdateBatch with composite primary key fail: %v", err)
+	}
+	expectedSQL = "UPDATE `enroll_raws` SET `score`=CASE WHEN `student_id` = ? AND `course_id` = ? THEN ? WHEN `student_id` = ? AND `course_id` = ? THEN ? ELSE `score` END,`updated_at`=? " +
+		"WHERE ((`student_id` = ? AND `course_id` = ?) OR (`student_id` = ? AND `course_id` = ?))"
+	if stmt.SQL != expectedSQL {
+		t.Errorf("SQL expects %q got %q", expectedSQL, stmt.SQL)
+	}
+
+	sqlDB := sql.OpenDB(testRowsConnector{})
+	defer sqlDB.Close()
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+	d := u.getInstance(conn)
+	d.UseModel(User{})
+	users = append(users, &User{ID: 3, Name: "carol"})
+	if info, err := d.UpdateBatch(users, 2, u.Name); err != nil || info.RowsAffected != 2 {
+		t.Errorf("UpdateBatch in 2 batches expects rows affected 2 got %d, %v", info.RowsAffected, err)
+	}
+
+	accounts := []*AccountRaw{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}
+	stmt, err = account.ToSQL(func(tx Dao) error { _, err := tx.UpdateBatch(accounts, 0); return err })
+	expectedSQL = "UPDATE `account` SET `name`=CASE `id` WHEN ? THEN ? WHEN ? THEN ? ELSE `name` END,`version`=`version` + 1 " +
+		"WHERE `account`.`id` IN (?,?) AND `account`.`is_deleted` = ?"
+	if err != nil || stmt.SQL != expectedSQL {
+		t.Errorf("UpdateBatch of partial records expects to skip create time and soft delete columns, SQL expects %q got %q, %v", expectedSQL, stmt.SQL, err)
+	}
+
+	members := []*MemberRaw{{ID: 1, Name: "alice"}}
+	stmt, err = member.WithTenant(int64(7)).ToSQL(func(tx Dao) error { _, err := tx.UpdateBatch(members, 0); return err })
+	expectedSQL = "UPDATE `member` SET `name`=CASE `id` WHEN ? THEN ? ELSE `name` END WHERE `member`.`id` = ? AND `member`.`tenant_id` = ?"
+	if err != nil || stmt.SQL != expectedSQL {
+		t.Errorf("UpdateBatch of tenant model expects to skip tenant column, SQL expects %q got %q, %v", expectedSQL, stmt.SQL, err)
+	}
+	if _, err := member.WithTenant(int64(7)).UpdateBatch(members, 0, member.TenantID); err == nil {
+		t.Errorf("UpdateBatch of tenant column expects error")
+	}
+
+	var sqls []string
+	orderDB := sql.OpenDB(testRowsConnector{sqls: &sqls})
+	defer orderDB.Close()
+	orderConn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: orderDB})
+	callbacks.RegisterDefaultCallbacks(orderConn, &callbacks.Config{})
+	o := order.getInstance(orderConn)
+	o.UseModel(OrderRaw{})
+	orders := []*OrderRaw{{ID: 1, UserID: 1, Amount: 10}, {ID: 2, UserID: 2, Amount: 20}, {ID: 3, UserID: 5, Amount: 30}}
+	if _, err := o.UpdateBatch(orders, 0, order.Amount); err != nil {
+		t.Errorf("UpdateBatch of sharded model fail: %v", err)
+	}
+	expectedSQLs := []string{
+		"UPDATE `orders_01` AS `orders` SET `amount`=CASE `id` WHEN ? THEN ? WHEN ? THEN ? ELSE `amount` END WHERE `orders`.`id` IN (?,?)",
+		"UPDATE `orders_02` AS `orders` SET `amount`=CASE `id` WHEN ? THEN ? ELSE `amount` END WHERE `orders`.`id` = ?",
+	}
+	if !reflect.DeepEqual(sqls, expectedSQLs) {
+		t.Errorf("UpdateBatch of sharded model expects updates in shards %q got %q", expectedSQLs, sqls)
+	}
+
+	if _, err := u.UpdateBatch([]*User{{Name: "modi"}}, 0); !errors.Is(err, gorm.ErrPrimaryKeyRequired) {
+		t.Errorf("UpdateBatch without primary key expects %v got %v", gorm.ErrPrimaryKeyRequired, err)
+	}
+	if _, err := u.UpdateBatch(users, 0, u.ID); err == nil {
+		t.Errorf("UpdateBatch of primary key expects error")
+	}
+}
+
+func TestDO_locking(t *testing.T) {
+	managers := employee.As("managers")
+	testcases := []struct {
+		Expr   Dao
+		Result string
+	}{
+		{
+			Expr:   u.Where(u.ID.Eq(1)).ForUpdate(),
+			Result: "SELECT * FROM `users_info` WHERE `id` = ? FOR UPDATE",
+		},
+		{
+			Expr:   u.ForShare().NoWait(),
+			Result: "SELECT * FROM `users_info` FOR SHARE NOWAIT",
+		},
+		{
+			Expr:   u.ForUpdate().SkipLocked().Limit(10),
+			Result: "SELECT * FROM `users_info` LIMIT 10 FOR UPDATE SKIP LOCKED",
+		},
+		{
+			Expr:   u.ForUpdate().SkipLocked().ForShare(),
+			Result: "SELECT * FROM `users_info` FOR SHARE SKIP LOCKED",
+		},
+		{
+			Expr:   u.ForUpdate().Of(u, teacher),
+			Result: "SELECT * FROM `users_info` FOR UPDATE OF `users_info`,`teacher`",
+		},
+		{
+			Expr:   employee.LeftJoin(managers, managers.ID.EqCol(employee.ManagerID)).ForUpdate().Of(employee, managers),
+			Result: "SELECT `employee`.`id`,`employee`.`name`,`employee`.`manager_id`,`employee`.`deleted_at` FROM `employee` LEFT JOIN `employee` AS `managers` ON `managers`.`id` = `employee`.`manager_id` WHERE `employee`.`deleted_at` IS NULL FOR UPDATE OF `employee`,`managers`",
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := tc.Expr.ToSQL(func(tx Dao) error { _, err := tx.Find(); return err })
+		if err != nil {
+			t.Errorf("locking query %s fail: %s", tc.Result, err)
+			continue
+		}
+		if stmt.SQL != tc.Result {
+			t.Errorf("SQL expects %s got %s", tc.Result, stmt.SQL)
+		}
+	}
+
+	for name, d := range map[string]Dao{
+		"SkipLocked without ForUpdate": u.SkipLocked(),
+		"NoWait without ForUpdate":     u.NoWait(),
+		"Of without ForUpdate":         u.Of(u),
+		"Of without table":             u.ForUpdate().Of(),
+		"NoWait with SkipLocked":       u.ForUpdate().SkipLocked().NoWait(),
+	} {
+		if err := d.(*DO).db.Error; err == nil {
+			t.Errorf("%s expects error", name)
+		}
+	}
+
+	db := u.db.Session(new(gorm.Session))
+	db.Config.Dialector = tests.DummyDialector{}
+	if err := u.getInstance(db).ForUpdate().(*DO).db.Error; err == nil {
+		t.Errorf("row locking of unsupported dialect expects error")
+	}
+
+	if err := u.Clauses(clause.Locking{Strength: "UPDATE"}).db.Error; err == nil {
+		t.Errorf("raw locking clause expects banned")
+	}
+}
+
+func TestDO_union(t *testing.T) {
+	testcases := []struct {
+		Expr   Dao
+		Result string
+	}{
+		{
+			Expr:   Union(u.Select(u.ID, u.Name).Where(u.Age.Lt(18)), u.Select(u.ID, u.Name).Where(u.Age.Gt(60))).Order(u.ID).Limit(10),
+			Result: "SELECT * FROM (SELECT `id`,`name` FROM `users_info` WHERE `age` < 18 UNION SELECT `id`,`name` FROM `users_info` WHERE `age` > 60) AS `users_info` ORDER BY `id` LIMIT 10",
+		},
+		{
+			Expr:   UnionAll(u.Where(u.Age.Lt(18)), u.Where(u.Age.Gt(60)).Order(u.Age).Limit(1)),
+			Result: "SELECT * FROM (SELECT * FROM `users_info` WHERE `age` < 18 UNION ALL (SELECT * FROM `users_info` WHERE `age` > 60 ORDER BY `age` LIMIT 1)) AS `users_info`",
+		},
+		{
+			Expr:   u.Select(u.ID).Union(u.DO.Select(u.ID).Where(u.Age.Gt(60))).Where(u.ID.Gt(1)),
+			Result: "SELECT * FROM (SELECT `id` FROM `users_info` UNION SELECT `id` FROM `users_info` WHERE `age` > 60) AS `users_info` WHERE `id` > 1",
+		},
+		{
+			Expr:   Table(Union(u.Select(u.ID), teacher.Select(teacher.ID)).As("t")).Select(),
+			Result: "SELECT * FROM (SELECT * FROM (SELECT `id` FROM `users_info` UNION SELECT `teacher`.`id` FROM `teacher`) AS `users_info`) AS `t`",
+		},
+		{
+			Expr:   u.DO.Where(columns{u.ID}.In(UnionAll(u.Select(u.ID), teacher.Select(teacher.ID)))),
+			Result: "SELECT * FROM `users_info` WHERE `id` IN (SELECT * FROM (SELECT `id` FROM `users_info` UNION ALL SELECT `teacher`.`id` FROM `teacher`) AS `users_info`)",
+		},
+		{
+			Expr:   employee.Select(employee.ID).Union(employee.Select(employee.ID).Where(employee.ManagerID.Eq(1))),
+			Result: "SELECT * FROM (SELECT `employee`.`id` FROM `employee` WHERE `employee`.`deleted_at` IS NULL UNION SELECT `employee`.`id` FROM `employee` WHERE `employee`.`manager_id` = 1 AND `employee`.`deleted_at` IS NULL) AS `employee`",
+		},
+		{
+			Expr:   account.Select(account.ID).UnionAll(account.Select(account.ID)),
+			Result: "SELECT * FROM (SELECT `account`.`id` FROM `account` WHERE `account`.`is_deleted` = 0 UNION ALL SELECT `account`.`id` FROM `account` WHERE `account`.`is_deleted` = 0) AS `account`",
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := tc.Expr.ToSQL(func(tx Dao) error { _, err := tx.Find(); return err })
+		if err != nil {
+			t.Errorf("union query %s fail: %s", tc.Result, err)
+			continue
+		}
+		if stmt.Interpolated != tc.Result {
+			t.Errorf("SQL expects %s got %s", tc.Result, stmt.Interpolated)
+		}
+	}
+
+	for name, d := range map[string]Dao{
+		"different columns":        Union(u.Select(u.ID), u.Select(u.ID, u.Name)),
+		"different columns of all": UnionAll(u.Select(u.ID), u.Select()),
+		"limit on sqlite":          UnionAll(u.getInstance(sqliteDB).Select(u.ID), u.getInstance(sqliteDB).Select(u.ID).Limit(1)),
+	} {
+		if err := d.underlyingDB().Error; err == nil {
+			t.Errorf("union of %s expects error", name)
+		}
+	}
+
+	for list, expected := range map[string]int{
+		"`id`,`name`":                   2,
+		"COUNT(`id`,`name`) AS `c`,`x`": 2,
+		"'a,b' AS `s`":                  1,
+		"`users`.*,`id`":                -1,
+		"*":                             -1,
+	} {
+		if n := countColumns(list); n != expected {
+			t.Errorf("columns of %s expects %d got %d", list, expected, n)
+		}
+	}
+}
+
+func TestDO_cte(t *testing.T) {
+	adults := With("adults", u.Select(u.ID, u.Name).Where(u.Age.Gte(18)))
+	managers := With("managers", employee.Select(employee.ID).Where(employee.ManagerID.Eq(0)))
+	adultID := field.NewUint("adults", "id")
+	tree := WithRecursive("tree",
+		u.Where(u.ID.Eq(1)),
+		u.Select(field.NewField("users_info", "*")).Join(TableRef("tree"), field.NewUint("tree", "id").EqCol(u.Age)),
+	)
+	treeSQL := "`tree` AS (SELECT * FROM `users_info` WHERE `id` = 1 UNION ALL SELECT `users_info`.* FROM `users_info` INNER JOIN `tree` ON `tree`.`id` = `age`)"
+
+	testcases := []struct {
+		Expr   Dao
+		Result string
+	}{
+		{
+			Expr:   adults,
+			Result: "WITH `adults` AS (SELECT `id`,`name` FROM `users_info` WHERE `age` >= 18) SELECT * FROM `adults`",
+		},
+		{
+			Expr:   adults.Select(adultID).Where(adultID.Gt(1)),
+			Result: "WITH `adults` AS (SELECT `id`,`name` FROM `users_info` WHERE `age` >= 18) SELECT `adults`.`id` FROM `adults` WHERE `adults`.`id` > 1",
+		},
+		{
+			Expr:   teacher.DO.Join(adults, adultID.EqCol(teacher.ID)),
+			Result: "WITH `adults` AS (SELECT `id`,`name` FROM `users_info` WHERE `age` >= 18) SELECT `teacher`.`id`,`teacher`.`name` FROM `teacher` INNER JOIN `adults` ON `adults`.`id` = `teacher`.`id`",
+		},
+		{
+			Expr:   tree,
+			Result: "WITH RECURSIVE " + treeSQL + " SELECT * FROM `tree`",
+		},
+		{
+			Expr:   Table(adults, tree).Select(),
+			Result: "WITH RECURSIVE `adults` AS (SELECT `id`,`name` FROM `users_info` WHERE `age` >= 18)," + treeSQL + " SELECT * FROM `adults`, `tree`",
+		},
+		{
+			Expr:   u.DO.With(tree, tree).Where(field.NewUint("tree", "id").EqCol(u.ID)).Select(u.ID),
+			Result: "WITH RECURSIVE " + treeSQL + " SELECT `id` FROM `users_info` WHERE `tree`.`id` = `id`",
+		},
+		{
+			Expr:   u.DO.Where(columns{u.ID}.In(teacher.DO.Select(teacher.ID).Join(adults, adultID.EqCol(teacher.ID)))),
+			Result: "SELECT * FROM `users_info` WHERE `id` IN (WITH `adults` AS (SELECT `id`,`name` FROM `users_info` WHERE `age` >= 18) SELECT `teacher`.`id` FROM `teacher` INNER JOIN `adults` ON `adults`.`id` = `teacher`.`id`)",
+		},
+		{
+			Expr:   managers,
+			Result: "WITH `managers` AS (SELECT `employee`.`id` FROM `employee` WHERE `employee`.`manager_id` = 0 AND `employee`.`deleted_at` IS NULL) SELECT * FROM `managers`",
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := tc.Expr.ToSQL(func(tx Dao) error { _, err := tx.Find(); return err })
+		if err != nil {
+			t.Errorf("cte query %s fail: %s", tc.Result, err)
+			continue
+		}
+		if stmt.Interpolated != tc.Result {
+			t.Errorf("SQL expects %s got %s", tc.Result, stmt.Interpolated)
+		}
+	}
+}
+
+func TestDO_window(t *testing.T) {
+	// top 3 scores of each age
+	ranked := u.Select(u.ID, u.Name, field.RowNumber(field.PartitionBy(u.Age).OrderBy(u.Score.Desc())).As("rn")).As("t")
+	stmt, err := Table(ranked).Where(field.NewInt("t", "rn").Lte(3)).ToSQL(func(tx Dao) error { _, err := tx.Find(); return err })
+	if err != nil {
+		t.Fatalf("window query fail: %s", err)
+	}
+	expected := "SELECT * FROM (SELECT `id`,`name`,ROW_NUMBER() OVER (PARTITION BY `age` ORDER BY `score` DESC) AS `rn` FROM `users_info`) AS `t` WHERE `t`.`rn` <= 3"
+	if stmt.Interpolated != expected {
+		t.Errorf("SQL expects %s got %s", expected, stmt.Interpolated)
+	}
+}
+
+func TestDO_case(t *testing.T) {
+	ageGroup := field.CaseString().When(u.Age.Lt(18), "minor").When(u.Age.Gte(60), "senior").Else("adult")
+	priority := field.CaseInt().When(u.Name.Eq("admin"), 0).Else(1)
+	find := func(tx Dao) error { _, err := tx.Find(); return err }
+
+	testcases := []struct {
+		Expr     Dao
+		Finisher func(tx Dao) error
+		Expected string
+	}{
+		{
+			Expr:     u.DO.Select(u.ID, ageGroup.As("age_group")),
+			Finisher: find,
+			Expected: "SELECT `id`,CASE WHEN `age` < 18 THEN \"minor\" WHEN `age` >= 60 THEN \"senior\" ELSE \"adult\" END AS `age_group` FROM `users_info`",
+		},
+		{
+			Expr:     u.DO.Order(priority, u.ID.Desc()),
+			Finisher: find,
+			Expected: "SELECT * FROM `users_info` ORDER BY CASE WHEN `name` = \"admin\" THEN 0 ELSE 1 END,`id` DESC",
+		},
+		{
+			Expr:     u.DO.Order(u.Name).Order(priority.Desc()),
+			Finisher: func(tx Dao) error { _, err := tx.First(); return err },
+			Expected: "SELECT * FROM `users_info` ORDER BY `name`,CASE WHEN `name` = \"admin\" THEN 0 ELSE 1 END DESC,`users_info`.`id` LIMIT 1",
+		},
+		{
+			Expr:     u.DO.Select(ageGroup.As("age_group"), u.ID.Count()).Group(ageGroup).Having(u.ID.Count().Gt(1)),
+			Finisher: find,
+			Expected: "SELECT CASE WHEN `age` < 18 THEN \"minor\" WHEN `age` >= 60 THEN \"senior\" ELSE \"adult\" END AS `age_group`,COUNT(`id`) FROM `users_info` GROUP BY CASE WHEN `age` < 18 THEN \"minor\" WHEN `age` >= 60 THEN \"senior\" ELSE \"adult\" END HAVING COUNT(`id`) > 1",
+		},
+		{
+			Expr:     u.DO.Group(u.Name).Group(ageGroup),
+			Finisher: find,
+			Expected: "SELECT * FROM `users_info` GROUP BY `name`,CASE WHEN `age` < 18 THEN \"minor\" WHEN `age` >= 60 THEN \"senior\" ELSE \"adult\" END",
+		},
+		{
+			Expr: u.DO.Where(u.ID.Eq(1)),
+			Finisher: func(tx Dao) error {
+				_, err := tx.Update(u.Age, field.CaseInt().When(u.Age.Lt(0), 0).Else(u.Age.Add(1)))
+				return err
+			},
+			Expected: "UPDATE `users_info` SET `age`=CASE WHEN `age` < 0 THEN 0 ELSE `age`+1 END WHERE `id` = 1",
+		},
+		{
+			Expr: u.DO.Where(u.ID.Eq(1)),
+			Finisher: func(tx Dao) error {
+				_, err := tx.UpdateSimple(u.Age.ValueExpr(field.CaseInt().When(u.Age.Gt(150), 150).Else(u.Age)))
+				return err
+			},
+			Expected: "UPDATE `users_info` SET `age`=CASE WHEN `age` > 150 THEN 150 ELSE `age` END WHERE `id` = 1",
+		},
+	}
+
+	for _, testcase := range testcases {
+		stmt, err := testcase.Expr.ToSQL(testcase.Finisher)
+		if err != nil {
+			t.Fatalf("case expression fail: %s", err)
+		}
+		if stmt.Interpolated != testcase.Expected {
+			t.Errorf("SQL expects %s got %s", testcase.Expected, stmt.Interpolated)
+		}
+	}
+}
+
+func TestDO_alias(t *testing.T) {
+	m := employee.As("managers")
+	if employee.Alias() != "" || m.Alias() != "managers" || m.TableName() != "employee" {
+		t.Fatalf("alias of employee expects managers of employee, got %q of %s", m.Alias(), m.TableName())
+	}
+	find := func(tx Dao) error { _, err := tx.Find(); return err }
+	// generated clone replace db of aliased query struct, eg: in transaction
+	cloned := m.DO
+	cloned.ReplaceDB(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}))
+
+	testcases := []struct {
+		Expr     Dao
+		Expected string
+	}{
+		{
+			Expr:     cloned.Where(m.Name.Eq("modi")),
+			Expected: "SELECT * FROM `employee` AS `managers` WHERE `managers`.`name` = \"modi\" AND `managers`.`deleted_at` IS NULL",
+		},
+		{
+			Expr:     employee.Select(employee.Name, m.Name.As("manager")).LeftJoin(m, m.ID.EqCol(employee.ManagerID)),
+			Expected: "SELECT `employee`.`name`,`managers`.`name` AS `manager` FROM `employee` LEFT JOIN `employee` AS `managers` ON `managers`.`id` = `employee`.`manager_id` WHERE `employee`.`deleted_at` IS NULL",
+		},
+		{
+			Expr:     m.Where(m.Name.Eq("modi")),
+			Expected: "SELECT * FROM `employee` AS `managers` WHERE `managers`.`name` = \"modi\" AND `managers`.`deleted_at` IS NULL",
+		},
+		{
+			// query struct aliased is not changed
+			Expr:     employee.Where(employee.Name.Eq("modi")),
+			Expected: "SELECT * FROM `employee` WHERE `employee`.`name` = \"modi\" AND `employee`.`deleted_at` IS NULL",
+		},
+	}
+
+	for _, testcase := range testcases {
+		stmt, err := testcase.Expr.ToSQL(find)
+		if err != nil {
+			t.Fatalf("alias query fail: %s", err)
+		}
+		if stmt.Interpolated != testcase.Expected {
+			t.Errorf("SQL expects %s got %s", testcase.Expected, stmt.Interpolated)
+		}
+	}
+}
+
+func TestDO_joinSubQuery(t *testing.T) {
+	// fields of subquery are bound to its alias by As of query struct
+	r := employee.As("r")
+	reports := employee.Select(employee.ManagerID, employee.ID.Count().As("reports")).Where(employee.Name.Neq("modi")).Group(employee.ManagerID).As("r")
+	find := func(tx Dao) error { _, err := tx.Find(); return err }
+
+	testcases := []struct {
+		Expr         Dao
+		ExpectedVars []interface{}
+		Expected     string
+	}{
+		{
+			Expr:         employee.Select(employee.Name, field.NewInt64("r", "reports")).Join(reports, r.ManagerID.EqCol(employee.ID)).Where(employee.ID.Gt(10)),
+			ExpectedVars: []interface{}{"modi", int64(10)},
+			Expected:     "SELECT `employee`.`name`,`r`.`reports` FROM `employee` INNER JOIN (SELECT `employee`.`manager_id`,COUNT(`employee`.`id`) AS `reports` FROM `employee` WHERE `employee`.`name` <> ? AND `employee`.`deleted_at` IS NULL GROUP BY `employee`.`manager_id`) AS `r` ON `r`.`manager_id` = `employee`.`id` WHERE `employee`.`id` > ? AND `employee`.`deleted_at` IS NULL",
+		},
+		{
+			Expr:         student.Select(student.Name).LeftJoin(u.DO.Select(u.ID).Where(u.Age.Gte(18)).As("adult"), field.NewUint("adult", "id").EqCol(student.ID), field.NewUint("adult", "id").Neq(1)),
+			ExpectedVars: []interface{}{18, uint(1)},
+			Expected:     "SELECT `student`.`name` FROM `student` LEFT JOIN (SELECT `id` FROM `users_info` WHERE `age` >= ?) AS `adult` ON `adult`.`id` = `student`.`id` AND `adult`.`id` <> ?",
+		},
+	}
+
+	for _, testcase := range testcases {
+		stmt, err := testcase.Expr.ToSQL(find)
+		if err != nil {
+			t.Fatalf("join subquery fail: %s", err)
+		}
+		if stmt.SQL != testcase.Expected {
+			t.Errorf("SQL expects %s got %s", testcase.Expected, stmt.SQL)
+		}
+		if !reflect.DeepEqual(stmt.Vars, testcase.ExpectedVars) {
+			t.Errorf("Vars expects %+v got %+v", testcase.ExpectedVars, stmt.Vars)
+		}
+	}
+
+	if _, err := student.Join(u.DO.Join(teacher).As("x"), student.ID.EqCol(student.ID)).Find(); !errors.Is(err, ErrEmptyCondition) {
+		t.Errorf("join subquery with error expects %s, got %v", ErrEmptyCondition, err)
+	}
+}
+
+func TestDO_filter(t *testing.T) {
+	type UserFilter struct {
+		Name    string `filter:"op:prefix"`
+		MinAge  int    `filter:"column:age;op:gte"`
+		MaxAge  int    `filter:"column:age;op:lt"`
+		IDs     []uint `filter:"column:id"`
+		Famous  *bool
+		Score   float64 `filter:"op:gt"`
+		Keyword string  `filter:"-"`
+	}
+	famous := false
+	find := func(tx Dao) error { _, err := tx.Find(); return err }
+
+	testcases := []struct {
+		Expr     Dao
+		Expected string
+	}{
+		{
+			Expr:     u.DO.WhereIf(false, u.Name.Eq("modi")).WhereIf(true, u.Age.Gt(18)),
+			Expected: "SELECT * FROM `users_info` WHERE `age` > 18",
+		},
+		{
+			Expr:     u.DO.FilterByExample(&User{Name: "modi", Age: 18}),
+			Expected: "SELECT * FROM `users_info` WHERE `users_info`.`name` = \"modi\" AND `users_info`.`age` = 18",
+		},
+		{
+			Expr:     u.DO.FilterByExample(User{Name: "modi", Address: "Paris"}, FilterLike(), FilterZero("Famous")),
+			Expected: "SELECT * FROM `users_info` WHERE `users_info`.`name` LIKE \"%modi%\" AND `users_info`.`address` LIKE \"%Paris%\" AND `users_info`.`famous` = false",
+		},
+		{
+			Expr:     u.DO.FilterByExample(&UserFilter{Name: "mo", MinAge: 18, MaxAge: 60, IDs: []uint{1, 2}, Famous: &famous, Keyword: "k"}),
+			Expected: "SELECT * FROM `users_info` WHERE `users_info`.`name` LIKE \"mo%\" AND `users_info`.`age` >= 18 AND `users_info`.`age` < 60 AND `users_info`.`id` IN (1,2) AND `users_info`.`famous` = false",
+		},
+		{
+			// metacharacters of LIKE are matched literally
+			Expr:     u.DO.FilterByExample(&UserFilter{Name: `50%_off\`}),
+			Expected: "SELECT * FROM `users_info` WHERE `users_info`.`name` LIKE \"50\\%\\_off\\\\%\"",
+		},
+		{
+			Expr:     u.getInstance(sqliteDB).FilterByExample(User{Name: "%"}, FilterLike()),
+			Expected: "SELECT * FROM `users_info` WHERE `users_info`.`name` LIKE \"%\\%%\" ESCAPE '\\'",
+		},
+		{
+			Expr:     u.DO.FilterByExample(&UserFilter{IDs: []uint{}}).FilterByExample((*User)(nil)),
+			Expected: "SELECT * FROM `users_info`",
+		},
+		{
+			// columns are qualified by alias of table
+			Expr:     employee.As("e").FilterByExample(&EmployeeRaw{ManagerID: 1}),
+			Expected: "SELECT * FROM `employee` AS `e` WHERE `e`.`manager_id` = 1 AND `e`.`deleted_at` IS NULL",
+		},
+	}
+
+	for _, testcase := range testcases {
+		stmt, err := testcase.Expr.ToSQL(find)
+		if err != nil {
+			t.Fatalf("filter fail: %s", err)
+		}
+		if stmt.Interpolated != testcase.Expected {
+			t.Errorf("SQL expects %s got %s", testcase.Expected, stmt.Interpolated)
+		}
+	}
+
+	for _, example := range []interface{}{
+		1,
+		struct{ Unknown string }{"x"},
+		struct {
+			Age int `filter:"op:between"`
+		}{1},
+		struct {
+			Age int `filter:"op:in"`
+		}{1},
+	} {
+		if _, err := u.DO.FilterByExample(example).Find(); err == nil {
+			t.Errorf("filter by %+v expects error, got nil", example)
+		}
+	}
+}
+
+func TestDO_spec(t *testing.T) {
+	testcases := []struct {
+		Filter   string
+		Sort     string
+		Allowed  []string
+		Expected string
+		Err      error
+	}{
+		{
+			Filter:   "age>18,name~bob",
+			Sort:     "-register_at,name",
+			Allowed:  []string{AllowAll},
+			Expected: "SELECT * FROM `users_info` WHERE `age` > 18 AND `name` LIKE \"%bob%\" ORDER BY `register_at` DESC,`name`",
+		},
+		{
+			// value of ~ is matched literally
+			Filter:   "name~100%_",
+			Allowed:  []string{"name"},
+			Expected: "SELECT * FROM `users_info` WHERE `name` LIKE \"%100\\%\\_%\"",
+		},
+		{
+			Filter:   " id=1|2|3 , famous=true, score>=60.5, age!=20|30, register_at<2021-10-01 ",
+			Sort:     "+id",
+			Allowed:  []string{"id", "famous", "score", "age", "register_at"},
+			Expected: "SELECT * FROM `users_info` WHERE `id` IN (1,2,3) AND `famous` = true AND `score` >= 60.500000 AND `age` NOT IN (20,30) AND `register_at` < \"2021-10-01 00:00:00\" ORDER BY `id`",
+		},
+		{
+			Filter:   "",
+			Sort:     "",
+			Expected: "SELECT * FROM `users_info`",
+		},
+		{Filter: "password=x", Allowed: []string{AllowAll}, Err: ErrFieldNotAllowed},
+		{Filter: "name=bob", Allowed: []string{"age"}, Err: ErrFieldNotAllowed},
+		{Sort: "-name", Allowed: []string{"age"}, Err: ErrFieldNotAllowed},
+		// no column is allowed without allowed columns
+		{Filter: "name=bob", Err: ErrFieldNotAllowed},
+		{Sort: "name", Err: ErrFieldNotAllowed},
+		{Filter: "age>eighteen", Allowed: []string{AllowAll}, Err: ErrInvalidSpec},
+		{Filter: "age~1", Allowed: []string{AllowAll}, Err: ErrInvalidSpec},
+		{Filter: "age", Allowed: []string{AllowAll}, Err: ErrInvalidSpec},
+		{Filter: ">18", Allowed: []string{AllowAll}, Err: ErrInvalidSpec},
+		{Filter: "age!18", Allowed: []string{AllowAll}, Err: ErrInvalidSpec},
+	}
+
+	for _, testcase := range testcases {
+		conds, err := ParseFilter(u, testcase.Filter, testcase.Allowed...)
+		var orders []field.Expr
+		if err == nil {
+			orders, err = ParseSort(u, testcase.Sort, testcase.Allowed...)
+		}
+		if testcase.Err != nil {
+			if !errors.Is(err, testcase.Err) {
+				t.Errorf("filter %q sort %q expects %s, got %v", testcase.Filter, testcase.Sort, testcase.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("filter %q sort %q fail: %s", testcase.Filter, testcase.Sort, err)
+		}
+
+		stmt, err := u.DO.Where(conds...).Order(orders...).ToSQL(func(tx Dao) error { _, err := tx.Find(); return err })
+		if err != nil {
+			t.Fatalf("query by spec fail: %s", err)
+		}
+		if stmt.Interpolated != testcase.Expected {
+			t.Errorf("SQL expects %s got %s", testcase.Expected, stmt.Interpolated)
+		}
+	}
+}
+
+func TestDO_scanInto(t *testing.T) {
+	type UserStats struct {
+		Name   string
+		AvgAge float64 `gorm:"column:avg_age"`
+	}
+
+	sqlDB := sql.OpenDB(testRowsConnector{
+		columns: []string{"name", "avg_age"},
+		rows:    [][]driver.Value{{"alice", float64(18.5)}, {"bob", float64(20)}},
+	})
+	defer sqlDB.Close()
+
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+	callbacks.RegisterDefaultCallbacks(conn, &callbacks.Config{})
+	d := u.getInstance(conn)
+	d.UseModel(User{})
+	query := d.Select(u.Name, u.Age.Avg().As("avg_age")).Group(u.Name)
+
+	var stats []UserStats
+	if err := query.ScanInto(&stats); err != nil {
+		t.Fatalf("ScanInto fail: %v", err)
+	}
+	if len(stats) != 2 || stats[0] != (UserStats{Name: "alice", AvgAge: 18.5}) || stats[1].Name != "bob" {
+		t.Errorf("ScanInto expects 2 stats got %+v", stats)
+	}
+
+	var stat UserStats
+	if err := query.ScanInto(&stat); err != nil || stat != (UserStats{Name: "alice", AvgAge: 18.5}) {
+		t.Errorf("ScanInto struct expects first row got %+v, %v", stat, err)
+	}
+
+	var unmapped []struct{ Name string }
+	if err := query.ScanInto(&unmapped); !errors.Is(err, ErrProjectionMismatch) || !strings.Contains(err.Error(), "avg_age") {
+		t.Errorf("ScanInto with unmapped column expects %s got %v", ErrProjectionMismatch, err)
+	}
+
+	var missing []struct {
+		Name   string
+		AvgAge float64
+		Total  int
+	}
+	if err := query.ScanInto(&missing); !errors.Is(err, ErrProjectionMismatch) || !strings.Contains(err.Error(), "Total") {
+		t.Errorf("ScanInto with missing field expects %s got %v", ErrProjectionMismatch, err)
+	}
+}
+
+// testDriverError driver error with error code like *mysql.MySQLError
+type testDriverError struct{ Number uint16 }
+
+func (e *testDriverError) Error() string { return fmt.Sprintf("Error %d", e.Number) }
+
+func TestTransactionWithRetry(t *testing.T) {
+	sqlDB := sql.OpenDB(testRowsConnector{})
+	defer sqlDB.Close()
+	conn, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB})
+
+	deadlock := &testDriverError{Number: 1213}
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Microsecond}
+	testcases := []struct {
+		Name     string
+		Errs     []error // errors returned by attempts in order, nil after them
+		Policy   RetryPolicy
+		Attempts int
+		Err      error
+	}{
+		{Name: "success", Policy: policy, Attempts: 1},
+		{Name: "retry deadlock", Errs: []error{deadlock, fmt.Errorf("insert: %w", deadlock)}, Policy: policy, Attempts: 3},
+		{Name: "exhausted", Errs: []error{deadlock, deadlock, deadlock, deadlock}, Policy: policy, Attempts: 3, Err: deadlock},
+		{Name: "not retryable", Errs: []error{&testDriverError{Number: 1062}}, Policy: policy, Attempts: 1, Err: &testDriverError{Number: 1062}},
+		{Name: "custom retryable", Errs: []error{gorm.ErrRecordNotFound}, Attempts: 2, Policy: RetryPolicy{
+			BaseDelay: time.Microsecond,
+			Retryable: func(err error) bool { return errors.Is(err, gorm.ErrRecordNotFound) },
+		}},
+	}
+
+	for _, testcase := range testcases {
+		var attempts int
+		err := TransactionWithRetry(conn, func(tx *gorm.DB) error {
+			if _, inTx := tx.Statement.ConnPool.(gorm.TxCommitter); !inTx {
+				t.Errorf("%s: fc expects to run in transaction", testcase.Name)
+			}
+			attempts++
+			if attempts <= len(testcase.Errs) {
+				return testcase.Errs[attempts-1]
+			}
+			return nil
+		}, testcase.Policy)
+
+		if attempts != testcase.Attempts {
+			t.Errorf("%s: expects %d attempts got %d", testcase.Name, testcase.Attempts, attempts)
+		}
+		if testcase.Err == nil && err != nil || testcase.Err != nil && !strings.Contains(fmt.Sprint(err), testcase.Err.Error()) {
+			t.Errorf("%s: expects error %v got %v", testcase.Name, testcase.Err, err)
+		}
+	}
+
+	// savepoints are not supported by dialector of test
+	var attempts int
+	_ = conn.Session(&gorm.Session{DisableNestedTransaction: true}).Transaction(func(tx *gorm.DB) error {
+		return TransactionWithRetry(tx, func(*gorm.DB) error { attempts++; return deadlock }, policy)
+	})
+	if attempts != 1 {
+		t.Errorf("nested transaction expects 1 attempt got %d", attempts)
+	}
+
+	// backoff is stopped by context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	attempts = 0
+	err := TransactionWithRetry(conn.WithContext(ctx), func(*gorm.DB) error { attempts++; return deadlock }, RetryPolicy{BaseDelay: time.Hour})
+	if attempts != 1 || !errors.Is(err, deadlock) {
+		t.Errorf("canceled context expects 1 attempt and %v got %d attempts, %v", deadlock, attempts, err)
+	}
+
+	for dialect, expected := range map[string]bool{"mysql": true, "postgres": false, "sqlite": false} {
+		if retryable := IsRetryableTxError(dialect, deadlock); retryable != expected {
+			t.Errorf("IsRetryableTxError of %s expects %t got %t", dialect, expected, retryable)
+		}
+	}
+	// lock wait timeout of mysql is not retried, but 1205 of sqlserver is deadlock
+	lockWaitTimeout := &testDriverError{Number: 1205}
+	if IsRetryableTxError("mysql", lockWaitTimeout) || !IsRetryableTxError("sqlserver", lockWaitTimeout) {
+		t.Errorf("IsRetryableTxError of 1205 expects false for mysql and true for sqlserver")
+	}
+}
+
 func TestDO_methods(t *testing.T) {
 	testcases := []struct {
 		Expr         subQuery
@@ -350,6 +2232,17 @@ func TestDO_methods(t *testing.T) {
 			Result:       "SELECT `student`.`id`,`student`.`name`,`teacher`.`name` AS `teacher_name` FROM `student` INNER JOIN `teacher` ON `student`.`instructor` = `teacher`.`id` LEFT JOIN `teacher` ON `student`.`id` > ?",
 			ExpectedVars: []interface{}{int64(100)},
 		},
+		// ======================== soft delete ========================
+		{
+			Expr:         account.OnlyDeleted().Where(account.ID.Eq(1)),
+			ExpectedVars: []interface{}{0, int64(1)},
+			Result:       "WHERE `account`.`is_deleted` <> ? AND `account`.`id` = ?",
+		},
+		{
+			Expr:         account.WithDeleted().Where(account.Name.Eq("tom")),
+			ExpectedVars: []interface{}{"tom"},
+			Result:       "WHERE `account`.`name` = ?",
+		},
 	}
 
 	// _ = u.Update(u.Age, u.Age.Add(1))
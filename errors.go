@@ -5,4 +5,52 @@ import "errors"
 var (
 	// ErrEmptyCondition empty condition
 	ErrEmptyCondition = errors.New("empty condition")
+
+	// ErrNoSoftDelete model has no soft delete field
+	ErrNoSoftDelete = errors.New("model has no soft delete field")
+
+	// ErrNoVersion model has no version field
+	ErrNoVersion = errors.New("model has no version field")
+
+	// ErrOptimisticLock no row matched with the expected version, the row may have been modified by others
+	ErrOptimisticLock = errors.New("optimistic lock: row has been modified or not exists")
+
+	// ErrNoTenantField model has no tenant field
+	ErrNoTenantField = errors.New("model has no tenant field")
+
+	// ErrNoTenant tenant is not specified for tenant scoped model, use WithTenant or UnscopedTenant
+	ErrNoTenant = errors.New("tenant not specified")
+
+	// ErrCrossTenant value belongs to another tenant
+	ErrCrossTenant = errors.New("value belongs to another tenant")
+
+	// ErrTenantType type of tenant id doesn't match tenant field
+	ErrTenantType = errors.New("tenant id type mismatch")
+
+	// ErrNoSharding model is not sharded
+	ErrNoSharding = errors.New("model is not sharded")
+
+	// ErrNoShardingKey sharding key is not found in conditions or values, specify it or use FanOut
+	ErrNoShardingKey = errors.New("sharding key not found")
+
+	// ErrCrossShard sharding key values belong to different shards
+	ErrCrossShard = errors.New("sharding key values belong to different shards")
+
+	// ErrInvalidCursor cursor is malformed or doesn't match the order columns
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrNoCache cache is not configured, use WithCache
+	ErrNoCache = errors.New("cache not configured")
+
+	// ErrNoStatement no statement is rendered by finisher in ToSQL
+	ErrNoStatement = errors.New("no statement rendered")
+
+	// ErrInvalidSpec filter or sort spec is malformed or its value doesn't match type of field
+	ErrInvalidSpec = errors.New("invalid filter or sort spec")
+
+	// ErrFieldNotAllowed field of filter or sort spec is unknown or not allowed
+	ErrFieldNotAllowed = errors.New("field not allowed")
+
+	// ErrProjectionMismatch selected columns don't match fields of destination of ScanInto
+	ErrProjectionMismatch = errors.New("selected columns don't match fields of destination")
 )
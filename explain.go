@@ -0,0 +1,252 @@
+package gen
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ExplainPlan query plan of statement, steps are in order of the plan output
+type ExplainPlan struct {
+	Steps []ExplainStep
+	Raw   string // raw output of EXPLAIN
+}
+
+// ExplainStep table access step of query plan
+type ExplainStep struct {
+	Table      string
+	AccessType string // MySQL: ALL, index, range, ref, eq_ref, const...; Postgres: Seq Scan, Index Scan...
+	Key        string // index used, empty if no index is used
+	Rows       int64  // estimated rows
+	ActualRows int64  // actual rows, only available in ExplainAnalyze
+	FullScan   bool   // full table scan
+}
+
+// FullScan check if any table is fully scanned
+func (p *ExplainPlan) FullScan() bool {
+	for _, step := range p.Steps {
+		if step.FullScan {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesIndex check if index is used by any step
+func (p *ExplainPlan) UsesIndex(key string) bool {
+	for _, step := range p.Steps {
+		if step.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// explainPrefixes EXPLAIN prefixes of dialects, [explain, explain analyze]
+var explainPrefixes = map[string][2]string{
+	"mysql":    {"EXPLAIN FORMAT=JSON ", "EXPLAIN ANALYZE FORMAT=JSON "},
+	"postgres": {"EXPLAIN (FORMAT JSON) ", "EXPLAIN (ANALYZE, FORMAT JSON) "},
+}
+
+// Explain return query plan of Find, the query is not executed
+func (d *DO) Explain() (plan *ExplainPlan, err error) {
+	if ok, err := d.intercept("Explain",
+		func(d *DO) (err error) { plan, err = d.Explain(); return err },
+		func(d *DO) error { _, err := d.Explain(); return err },
+	); ok {
+		return plan, err
+	}
+	return d.explain(false)
+}
+
+// ExplainAnalyze return query plan of Find with actual rows, the query is executed,
+// MySQL 8.3+ is required to output it in JSON
+func (d *DO) ExplainAnalyze() (plan *ExplainPlan, err error) {
+	if ok, err := d.intercept("ExplainAnalyze",
+		func(d *DO) (err error) { plan, err = d.ExplainAnalyze(); return err },
+		func(d *DO) error { _, err := d.ExplainAnalyze(); return err },
+	); ok {
+		return plan, err
+	}
+	return d.explain(true)
+}
+
+func (d *DO) explain(analyze bool) (*ExplainPlan, error) {
+	prefixes, ok := explainPrefixes[d.db.Dialector.Name()]
+	if !ok {
+		return nil, fmt.Errorf("explain: unsupported dialect %s", d.db.Dialector.Name())
+	}
+	if d.db.DryRun {
+		return nil, gorm.ErrDryRunModeUnsupported
+	}
+
+	stmt, err := d.ToSQL(func(tx Dao) error { _, err := tx.Find(); return err })
+	if err != nil {
+		return nil, err
+	}
+	prefix := prefixes[0]
+	if analyze {
+		prefix = prefixes[1]
+	}
+
+	db := d.readDB()
+	rows, err := db.Statement.ConnPool.QueryContext(d.stmtContext(), prefix+stmt.SQL, stmt.Vars...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return parseExplain(rows)
+}
+
+// parseExplain parse EXPLAIN output, which is a JSON document in single column or a table of MySQL
+func parseExplain(rows *sql.Rows) (*ExplainPlan, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		raw     strings.Builder
+		tabular []map[string]string
+	)
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			row[strings.ToLower(column)] = values[i].String
+		}
+		tabular = append(tabular, row)
+		if raw.Len() > 0 {
+			raw.WriteString("\n")
+		}
+		raw.WriteString(strings.Join(nullStrings(values), "\t"))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(columns) > 1 {
+		return &ExplainPlan{Steps: parseExplainTable(tabular), Raw: raw.String()}, nil
+	}
+	return parseExplainJSON(raw.String())
+}
+
+func nullStrings(values []sql.NullString) []string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = v.String
+	}
+	return strs
+}
+
+// parseExplainTable parse traditional tabular EXPLAIN output of MySQL
+func parseExplainTable(rows []map[string]string) []ExplainStep {
+	steps := make([]ExplainStep, 0, len(rows))
+	for _, row := range rows {
+		rowCount, _ := strconv.ParseInt(row["rows"], 10, 64)
+		steps = append(steps, ExplainStep{
+			Table:      row["table"],
+			AccessType: row["type"],
+			Key:        row["key"],
+			Rows:       rowCount,
+			FullScan:   row["type"] == "ALL",
+		})
+	}
+	return steps
+}
+
+// parseExplainJSON parse JSON EXPLAIN output of MySQL (format version 1 and 2) or Postgres
+func parseExplainJSON(raw string) (*ExplainPlan, error) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("explain: parse output fail: %w", err)
+	}
+
+	plan := &ExplainPlan{Raw: raw}
+	walkExplain(doc, func(node map[string]interface{}) {
+		switch {
+		case node["Node Type"] != nil: // postgres
+			if node["Relation Name"] == nil {
+				return
+			}
+			accessType := jsonString(node["Node Type"])
+			plan.Steps = append(plan.Steps, ExplainStep{
+				Table:      jsonString(node["Relation Name"]),
+				AccessType: accessType,
+				Key:        jsonString(node["Index Name"]),
+				Rows:       jsonInt(node["Plan Rows"]),
+				ActualRows: jsonInt(node["Actual Rows"]),
+				FullScan:   accessType == "Seq Scan",
+			})
+		case node["access_type"] != nil && node["table_name"] != nil: // mysql
+			accessType, key, rows := jsonString(node["access_type"]), node["key"], node["rows_examined_per_scan"]
+			if key == nil { // format version 2
+				key = node["index_name"]
+			}
+			if rows == nil {
+				rows = node["estimated_rows"]
+			}
+			plan.Steps = append(plan.Steps, ExplainStep{
+				Table:      jsonString(node["table_name"]),
+				AccessType: accessType,
+				Key:        jsonString(key),
+				Rows:       jsonInt(rows),
+				ActualRows: jsonInt(node["actual_rows"]),
+				FullScan:   accessType == "ALL" || accessType == "table",
+			})
+		}
+	})
+	return plan, nil
+}
+
+// walkExplain visit objects of JSON document in depth-first order
+func walkExplain(doc interface{}, visit func(node map[string]interface{})) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		visit(v)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		// visit children in stable order, plans are nested in a few keys only
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkExplain(v[key], visit)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkExplain(item, visit)
+		}
+	}
+}
+
+func jsonString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func jsonInt(v interface{}) int64 {
+	switch v := v.(type) {
+	case float64:
+		return int64(v)
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return int64(f)
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package gen
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// exprList expressions joined by comma
+type exprList []clause.Expression
+
+func (l exprList) Build(builder clause.Builder) {
+	for i, e := range l {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		e.Build(builder)
+	}
+}
+
+// withExprs add expressions with vars (eg: CASE expression) to ORDER BY or GROUP BY clause, which are
+// kept in AfterNameExpression as columns of gorm clauses cannot carry vars, and columns added before
+// are moved into expressions to keep the order. Columns added by gorm later (eg: primary key of First)
+// are built after expressions
+func withExprs(db *gorm.DB, name string, exprs []clause.Expression) *gorm.DB {
+	tx := db.Clauses()
+	c := tx.Statement.Clauses[name]
+	items, _ := c.AfterNameExpression.(exprList)
+	items = append(exprList(nil), items...)
+
+	switch e := c.Expression.(type) {
+	case clause.OrderBy:
+		if len(e.Columns) > 0 || e.Expression != nil {
+			items = append(items, e)
+		}
+		c.Expression = clause.OrderBy{}
+	case clause.GroupBy:
+		if len(e.Columns) > 0 {
+			items = append(items, clause.GroupBy{Columns: e.Columns})
+		}
+		c.Expression = clause.GroupBy{Having: e.Having}
+	case nil:
+		if name == "ORDER BY" {
+			c.Expression = clause.OrderBy{}
+		} else {
+			c.Expression = clause.GroupBy{}
+		}
+	}
+
+	c.Name = name
+	c.AfterNameExpression = append(items, exprs...)
+	c.Builder = exprsBuilder(name)
+	tx.Statement.Clauses[name] = c
+	return tx
+}
+
+// exprsBuilder build ORDER BY or GROUP BY clause with expressions before columns,
+// name of clause is not kept by GroupBy without columns
+func exprsBuilder(name string) clause.ClauseBuilder {
+	return func(c clause.Clause, builder clause.Builder) {
+		builder.WriteString(name)
+		builder.WriteByte(' ')
+		c.AfterNameExpression.Build(builder)
+
+		switch e := c.Expression.(type) {
+		case clause.OrderBy:
+			if len(e.Columns) > 0 || e.Expression != nil {
+				builder.WriteByte(',')
+				e.Build(builder)
+			}
+		case clause.GroupBy:
+			for _, column := range e.Columns {
+				builder.WriteByte(',')
+				builder.WriteQuoted(column)
+			}
+			if len(e.Having) > 0 {
+				builder.WriteString(" HAVING ")
+				clause.Where{Exprs: e.Having}.Build(builder)
+			}
+		}
+	}
+}
+
+// hasExprs check if clause has expressions with vars
+func hasExprs(db *gorm.DB, name string) bool {
+	_, ok := db.Statement.Clauses[name].AfterNameExpression.(exprList)
+	return ok
+}
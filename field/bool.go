@@ -41,3 +41,7 @@ func (field Bool) Value(value bool) AssignExpr {
 func (field Bool) Zero() AssignExpr {
 	return field.value(false)
 }
+
+func (field Bool) As(alias string) Expr {
+	return Bool{field.expr.As(alias).(expr)}
+}
@@ -0,0 +1,274 @@
+package field
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// caseWhen CASE WHEN cond THEN value ... ELSE value END
+type caseWhen struct {
+	whens   []interface{} // pairs of condition and value
+	els     interface{}
+	hasElse bool
+}
+
+func (c caseWhen) when(cond Expr, then interface{}) caseWhen {
+	c.whens = append(append(make([]interface{}, 0, len(c.whens)+2), c.whens...), toVar(cond.RawExpr()), caseValue(then))
+	return c
+}
+
+func (c caseWhen) otherwise(value interface{}) caseWhen {
+	c.els, c.hasElse = caseValue(value), true
+	return c
+}
+
+func (c caseWhen) expr() expr {
+	var sql strings.Builder
+	sql.WriteString("CASE")
+	vars := append(make([]interface{}, 0, len(c.whens)+1), c.whens...)
+	for i := 0; i < len(c.whens); i += 2 {
+		sql.WriteString(" WHEN ? THEN ?")
+	}
+	if c.hasElse {
+		sql.WriteString(" ELSE ?")
+		vars = append(vars, c.els)
+	}
+	sql.WriteString(" END")
+	return expr{e: clause.Expr{SQL: sql.String(), Vars: vars}}
+}
+
+// caseValue value of THEN or ELSE, which is a value or an expression
+func caseValue(value interface{}) interface{} {
+	if e, ok := value.(Expr); ok {
+		return toVar(e.RawExpr())
+	}
+	return value
+}
+
+// toVar convert expression to var of clause.Expr, expressions except column and clause.Expr are built by
+// expressionVar, as they are bound as values by gorm
+func toVar(e interface{}) interface{} {
+	switch e := e.(type) {
+	case clause.Column, clause.Expr, *clause.Expr:
+		return e
+	case clause.Expression:
+		return expressionVar{e}
+	}
+	return e
+}
+
+// expressionVar build expression as var of clause.Expr, eg: conditions of WHEN
+type expressionVar struct{ e clause.Expression }
+
+// GormValue implement gorm.Valuer
+func (v expressionVar) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	tx := db.Session(&gorm.Session{Context: ctx})
+	// placeholders are bound by outer statement, so that bind vars are numbered in order
+	tx.Config.Dialector = placeholderDialector{tx.Dialector}
+	stmt := &gorm.Statement{DB: tx, Context: ctx, Table: db.Statement.Table, Schema: db.Statement.Schema, Clauses: map[string]clause.Clause{}}
+	v.e.Build(stmt)
+	return clause.Expr{SQL: stmt.SQL.String(), Vars: stmt.Vars}
+}
+
+// placeholderDialector dialector binds vars with ?
+type placeholderDialector struct{ gorm.Dialector }
+
+func (placeholderDialector) BindVarTo(writer clause.Writer, _ *gorm.Statement, _ interface{}) {
+	writer.WriteByte('?')
+}
+
+// ======================== CASE expression ========================
+
+// FieldCase CASE expression of generic result
+type FieldCase struct {
+	Field
+	c caseWhen
+}
+
+// Case return CASE expression, eg: field.Case().When(u.Age.Lt(18), "minor").Else("adult").As("age_group")
+func Case() FieldCase { return FieldCase{} }
+
+// When add WHEN cond THEN value, value can be an expression
+func (c FieldCase) When(cond Expr, then interface{}) FieldCase {
+	c.c = c.c.when(cond, then)
+	c.Field = Field{c.c.expr()}
+	return c
+}
+
+// Else add ELSE value, value can be an expression
+func (c FieldCase) Else(value interface{}) FieldCase {
+	c.c = c.c.otherwise(value)
+	c.Field = Field{c.c.expr()}
+	return c
+}
+
+// StringCase CASE expression of string result
+type StringCase struct {
+	String
+	c caseWhen
+}
+
+// CaseString return CASE expression of string result
+func CaseString() StringCase { return StringCase{} }
+
+// When add WHEN cond THEN value, value can be an expression
+func (c StringCase) When(cond Expr, then interface{}) StringCase {
+	c.c = c.c.when(cond, then)
+	c.String = String{c.c.expr()}
+	return c
+}
+
+// Else add ELSE value, value can be an expression
+func (c StringCase) Else(value interface{}) StringCase {
+	c.c = c.c.otherwise(value)
+	c.String = String{c.c.expr()}
+	return c
+}
+
+// IntCase CASE expression of int result, eg: order by status priority
+//
+//	o.Order(field.CaseInt().When(o.Status.Eq("urgent"), 0).When(o.Status.Eq("normal"), 1).Else(2), o.ID)
+type IntCase struct {
+	Int
+	c caseWhen
+}
+
+// CaseInt return CASE expression of int result
+func CaseInt() IntCase { return IntCase{} }
+
+// When add WHEN cond THEN value, value can be an expression
+func (c IntCase) When(cond Expr, then interface{}) IntCase {
+	c.c = c.c.when(cond, then)
+	c.Int = Int{c.c.expr()}
+	return c
+}
+
+// Else add ELSE value, value can be an expression
+func (c IntCase) Else(value interface{}) IntCase {
+	c.c = c.c.otherwise(value)
+	c.Int = Int{c.c.expr()}
+	return c
+}
+
+// Int64Case CASE expression of int64 result
+type Int64Case struct {
+	Int64
+	c caseWhen
+}
+
+// CaseInt64 return CASE expression of int64 result
+func CaseInt64() Int64Case { return Int64Case{} }
+
+// When add WHEN cond THEN value, value can be an expression
+func (c Int64Case) When(cond Expr, then interface{}) Int64Case {
+	c.c = c.c.when(cond, then)
+	c.Int64 = Int64{c.c.expr()}
+	return c
+}
+
+// Else add ELSE value, value can be an expression
+func (c Int64Case) Else(value interface{}) Int64Case {
+	c.c = c.c.otherwise(value)
+	c.Int64 = Int64{c.c.expr()}
+	return c
+}
+
+// UintCase CASE expression of uint result
+type UintCase struct {
+	Uint
+	c caseWhen
+}
+
+// CaseUint return CASE expression of uint result
+func CaseUint() UintCase { return UintCase{} }
+
+// When add WHEN cond THEN value, value can be an expression
+func (c UintCase) When(cond Expr, then interface{}) UintCase {
+	c.c = c.c.when(cond, then)
+	c.Uint = Uint{c.c.expr()}
+	return c
+}
+
+// Else add ELSE value, value can be an expression
+func (c UintCase) Else(value interface{}) UintCase {
+	c.c = c.c.otherwise(value)
+	c.Uint = Uint{c.c.expr()}
+	return c
+}
+
+// Float64Case CASE expression of float64 result
+type Float64Case struct {
+	Float64
+	c caseWhen
+}
+
+// CaseFloat64 return CASE expression of float64 result
+func CaseFloat64() Float64Case { return Float64Case{} }
+
+// When add WHEN cond THEN value, value can be an expression
+func (c Float64Case) When(cond Expr, then interface{}) Float64Case {
+	c.c = c.c.when(cond, then)
+	c.Float64 = Float64{c.c.expr()}
+	return c
+}
+
+// Else add ELSE value, value can be an expression
+func (c Float64Case) Else(value interface{}) Float64Case {
+	c.c = c.c.otherwise(value)
+	c.Float64 = Float64{c.c.expr()}
+	return c
+}
+
+// BoolCase CASE expression of bool result
+type BoolCase struct {
+	Bool
+	c caseWhen
+}
+
+// CaseBool return CASE expression of bool result
+func CaseBool() BoolCase { return BoolCase{} }
+
+// When add WHEN cond THEN value, value can be an expression
+func (c BoolCase) When(cond Expr, then interface{}) BoolCase {
+	c.c = c.c.when(cond, then)
+	c.Bool = Bool{c.c.expr()}
+	return c
+}
+
+// Else add ELSE value, value can be an expression
+func (c BoolCase) Else(value interface{}) BoolCase {
+	c.c = c.c.otherwise(value)
+	c.Bool = Bool{c.c.expr()}
+	return c
+}
+
+// TimeCase CASE expression of time result
+type TimeCase struct {
+	Time
+	c caseWhen
+}
+
+// CaseTime return CASE expression of time result
+func CaseTime() TimeCase { return TimeCase{} }
+
+// When add WHEN cond THEN value, value can be an expression
+func (c TimeCase) When(cond Expr, then interface{}) TimeCase {
+	c.c = c.c.when(cond, then)
+	c.Time = Time{c.c.expr()}
+	return c
+}
+
+// Else add ELSE value, value can be an expression
+func (c TimeCase) Else(value interface{}) TimeCase {
+	c.c = c.c.otherwise(value)
+	c.Time = Time{c.c.expr()}
+	return c
+}
+
+// ValueExpr assign value of expression to field, eg: u.Level.ValueExpr(field.CaseInt().When(u.Score.Gte(90), 1).Else(2))
+func (e expr) ValueExpr(value Expr) AssignExpr {
+	return e.value(value.RawExpr())
+}
@@ -342,6 +342,65 @@ func TestExpr_Build(t *testing.T) {
 			ExpectedVars: []interface{}{true},
 			Result:       "`male` OR ?",
 		},
+		// ======================== window ========================
+		{
+			Expr:   field.RowNumber(field.PartitionBy(field.NewUint("", "company_id")).OrderBy(field.NewFloat64("", "salary").Desc())).As("rn"),
+			Result: "ROW_NUMBER() OVER (PARTITION BY `company_id` ORDER BY `salary` DESC) AS `rn`",
+		},
+		{
+			Expr:   field.Rank(field.OrderBy(field.NewFloat64("user", "salary"))),
+			Result: "RANK() OVER (ORDER BY `user`.`salary`)",
+		},
+		{
+			Expr:         field.DenseRank(field.PartitionBy(field.NewUint("", "company_id"), field.NewString("", "title"))).Lte(3),
+			ExpectedVars: []interface{}{3},
+			Result:       "DENSE_RANK() OVER (PARTITION BY `company_id`,`title`) <= ?",
+		},
+		{
+			Expr:   field.Lag(field.NewFloat64("", "price"), 1, field.OrderBy(field.NewTime("", "date"))),
+			Result: "LAG(`price`,1) OVER (ORDER BY `date`)",
+		},
+		{
+			Expr:   field.Lead(field.NewFloat64("", "price"), 2, field.PartitionBy(field.NewString("", "symbol")).OrderBy(field.NewTime("", "date"))).As("next_price"),
+			Result: "LEAD(`price`,2) OVER (PARTITION BY `symbol` ORDER BY `date`) AS `next_price`",
+		},
+		{
+			Expr:   field.NewFloat64("", "salary").Sum().Over(field.PartitionBy(field.NewUint("", "company_id"))),
+			Result: "SUM(`salary`) OVER (PARTITION BY `company_id`)",
+		},
+		{
+			Expr:   field.NewFloat64("", "price").Avg().Over(field.OrderBy(field.NewTime("", "date")).Rows(field.Preceding(6), field.CurrentRow)).As("avg_7d"),
+			Result: "AVG(`price`) OVER (ORDER BY `date` ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) AS `avg_7d`",
+		},
+		{
+			Expr:   field.NewInt("", "id").Count().Over(field.OrderBy(field.NewInt("", "amount")).Range(field.UnboundedPreceding, field.Following(10))),
+			Result: "COUNT(`id`) OVER (ORDER BY `amount` RANGE BETWEEN UNBOUNDED PRECEDING AND 10 FOLLOWING)",
+		},
+		// ======================== case ========================
+		{
+			Expr:         field.CaseString().When(field.NewInt("", "age").Lt(18), "minor").Else("adult").As("age_group"),
+			ExpectedVars: []interface{}{18, "minor", "adult"},
+			Result:       "CASE WHEN `age` < ? THEN ? ELSE ? END AS `age_group`",
+		},
+		{
+			Expr:         field.CaseInt().When(field.NewString("", "status").Eq("urgent"), 0).When(field.NewString("", "status").In("normal", "low"), 1).Else(2).Desc(),
+			ExpectedVars: []interface{}{"urgent", 0, "normal", "low", 1, 2},
+			Result:       "CASE WHEN `status` = ? THEN ? WHEN `status` IN (?,?) THEN ? ELSE ? END DESC",
+		},
+		{
+			Expr:         field.CaseFloat64().When(field.NewBool("", "vip"), field.NewFloat64("", "price").Mul(0.8)).Else(field.NewFloat64("", "price")).Sum(),
+			ExpectedVars: []interface{}{0.8},
+			Result:       "SUM(CASE WHEN `vip` THEN `price`*? ELSE `price` END)",
+		},
+		{
+			Expr:   field.Case().When(field.NewInt("", "age").IsNull(), field.NewInt("", "default_age")),
+			Result: "CASE WHEN `age` IS NULL THEN `default_age` END",
+		},
+		{
+			Expr:         field.NewInt("", "level").ValueExpr(field.CaseInt().When(field.NewInt("", "score").Gte(90), 1).Else(2)),
+			ExpectedVars: []interface{}{90, 1, 2},
+			Result:       "`level` = CASE WHEN `score` >= ? THEN ? ELSE ? END",
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -382,6 +441,19 @@ func TestExpr_BuildColumn(t *testing.T) {
 	}
 }
 
+func TestExpr_AsKeepType(t *testing.T) {
+	if _, ok := field.NewUint("", "id").Avg().As("avg_id").(field.Float64); !ok {
+		t.Errorf("Avg().As() expects field.Float64")
+	}
+	if _, ok := field.NewUint("", "id").Count().As("total").(field.Int); !ok {
+		t.Errorf("Count().As() expects field.Int")
+	}
+	if _, ok := field.NewString("", "name").As("user_name").(field.String); !ok {
+		t.Errorf("String.As() expects field.String")
+	}
+	field.CheckBuildExpr(t, field.NewTime("", "created_at").Max().As("latest"), "MAX(`created_at`) AS `latest`", nil)
+}
+
 func BenchmarkExpr_Count(b *testing.B) {
 	id := field.NewUint("", "id")
 	for i := 0; i < b.N; i++ {
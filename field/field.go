@@ -57,3 +57,7 @@ func (field Field) toSlice(values ...ScanValuer) []interface{} {
 	}
 	return slice
 }
+
+func (field Field) As(alias string) Expr {
+	return Field{field.expr.As(alias).(expr)}
+}
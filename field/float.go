@@ -92,6 +92,10 @@ func (field Float64) toSlice(values ...float64) []interface{} {
 	return slice
 }
 
+func (field Float64) As(alias string) Expr {
+	return Float64{field.expr.As(alias).(expr)}
+}
+
 type Float32 Float64
 
 func (field Float32) Eq(value float32) Expr {
@@ -181,3 +185,7 @@ func (field Float32) toSlice(values ...float32) []interface{} {
 	}
 	return slice
 }
+
+func (field Float32) As(alias string) Expr {
+	return Float32{field.expr.As(alias).(expr)}
+}
@@ -118,6 +118,10 @@ func (field Int) toSlice(values ...int) []interface{} {
 	return slice
 }
 
+func (field Int) As(alias string) Expr {
+	return Int{field.expr.As(alias).(expr)}
+}
+
 type Int8 Int
 
 func (field Int8) Eq(value int8) Expr {
@@ -232,6 +236,10 @@ func (field Int8) toSlice(values ...int8) []interface{} {
 	return slice
 }
 
+func (field Int8) As(alias string) Expr {
+	return Int8{field.expr.As(alias).(expr)}
+}
+
 type Int16 Int
 
 func (field Int16) Eq(value int16) Expr {
@@ -346,6 +354,10 @@ func (field Int16) toSlice(values ...int16) []interface{} {
 	return slice
 }
 
+func (field Int16) As(alias string) Expr {
+	return Int16{field.expr.As(alias).(expr)}
+}
+
 type Int32 Int
 
 func (field Int32) Eq(value int32) Expr {
@@ -460,6 +472,10 @@ func (field Int32) toSlice(values ...int32) []interface{} {
 	return slice
 }
 
+func (field Int32) As(alias string) Expr {
+	return Int32{field.expr.As(alias).(expr)}
+}
+
 type Int64 Int
 
 func (field Int64) Eq(value int64) Expr {
@@ -574,6 +590,10 @@ func (field Int64) toSlice(values ...int64) []interface{} {
 	return slice
 }
 
+func (field Int64) As(alias string) Expr {
+	return Int64{field.expr.As(alias).(expr)}
+}
+
 type Uint Int
 
 func (field Uint) Eq(value uint) Expr {
@@ -688,6 +708,10 @@ func (field Uint) toSlice(values ...uint) []interface{} {
 	return slice
 }
 
+func (field Uint) As(alias string) Expr {
+	return Uint{field.expr.As(alias).(expr)}
+}
+
 type Uint8 Int
 
 func (field Uint8) Eq(value uint8) Expr {
@@ -802,6 +826,10 @@ func (field Uint8) toSlice(values ...uint8) []interface{} {
 	return slice
 }
 
+func (field Uint8) As(alias string) Expr {
+	return Uint8{field.expr.As(alias).(expr)}
+}
+
 type Uint16 Int
 
 func (field Uint16) Eq(value uint16) Expr {
@@ -916,6 +944,10 @@ func (field Uint16) toSlice(values ...uint16) []interface{} {
 	return slice
 }
 
+func (field Uint16) As(alias string) Expr {
+	return Uint16{field.expr.As(alias).(expr)}
+}
+
 type Uint32 Int
 
 func (field Uint32) Eq(value uint32) Expr {
@@ -1030,6 +1062,10 @@ func (field Uint32) toSlice(values ...uint32) []interface{} {
 	return slice
 }
 
+func (field Uint32) As(alias string) Expr {
+	return Uint32{field.expr.As(alias).(expr)}
+}
+
 type Uint64 Int
 
 func (field Uint64) Eq(value uint64) Expr {
@@ -1143,3 +1179,7 @@ func (field Uint64) toSlice(values ...uint64) []interface{} {
 	}
 	return slice
 }
+
+func (field Uint64) As(alias string) Expr {
+	return Uint64{field.expr.As(alias).(expr)}
+}
@@ -88,6 +88,10 @@ func (field String) toSlice(values []string) []interface{} {
 	return slice
 }
 
+func (field String) As(alias string) Expr {
+	return String{field.expr.As(alias).(expr)}
+}
+
 type Bytes String
 
 func (field Bytes) Eq(value []byte) Expr {
@@ -171,3 +175,7 @@ func (field Bytes) toSlice(values [][]byte) []interface{} {
 	}
 	return slice
 }
+
+func (field Bytes) As(alias string) Expr {
+	return Bytes{field.expr.As(alias).(expr)}
+}
@@ -148,3 +148,7 @@ func (field Time) toSlice(values ...time.Time) []interface{} {
 	}
 	return slice
 }
+
+func (field Time) As(alias string) Expr {
+	return Time{field.expr.As(alias).(expr)}
+}
@@ -0,0 +1,133 @@
+package field
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// Window window specification of window functions, eg:
+//
+//	field.PartitionBy(u.CompanyID).OrderBy(u.Salary.Desc()).Rows(field.UnboundedPreceding, field.CurrentRow)
+type Window struct {
+	partitionBy []Expr
+	orderBy     []Expr
+	frame       string
+}
+
+// PartitionBy return window partitioned by columns
+func PartitionBy(columns ...Expr) Window { return Window{}.PartitionBy(columns...) }
+
+// OrderBy return window ordered by columns
+func OrderBy(columns ...Expr) Window { return Window{}.OrderBy(columns...) }
+
+// PartitionBy partition window by columns
+func (w Window) PartitionBy(columns ...Expr) Window {
+	w.partitionBy = append(append([]Expr(nil), w.partitionBy...), columns...)
+	return w
+}
+
+// OrderBy order rows of partition by columns
+func (w Window) OrderBy(columns ...Expr) Window {
+	w.orderBy = append(append([]Expr(nil), w.orderBy...), columns...)
+	return w
+}
+
+// Rows specify frame of window in rows, eg: Rows(Preceding(2), CurrentRow)
+func (w Window) Rows(start, end FrameBound) Window {
+	w.frame = "ROWS BETWEEN " + start.sql + " AND " + end.sql
+	return w
+}
+
+// Range specify frame of window in range of values of ORDER BY column
+func (w Window) Range(start, end FrameBound) Window {
+	w.frame = "RANGE BETWEEN " + start.sql + " AND " + end.sql
+	return w
+}
+
+// build return window specification and vars, eg: PARTITION BY ? ORDER BY ? ROWS BETWEEN ...
+func (w Window) build() (string, []interface{}) {
+	var (
+		spec []string
+		vars []interface{}
+	)
+	if len(w.partitionBy) > 0 {
+		spec = append(spec, "PARTITION BY "+placeholders(len(w.partitionBy)))
+		for _, column := range w.partitionBy {
+			vars = append(vars, column.RawExpr())
+		}
+	}
+	if len(w.orderBy) > 0 {
+		spec = append(spec, "ORDER BY "+placeholders(len(w.orderBy)))
+		for _, column := range w.orderBy {
+			vars = append(vars, column.RawExpr())
+		}
+	}
+	if w.frame != "" {
+		spec = append(spec, w.frame)
+	}
+	return strings.Join(spec, " "), vars
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// FrameBound bound of window frame
+type FrameBound struct{ sql string }
+
+var (
+	// UnboundedPreceding the first row of partition
+	UnboundedPreceding = FrameBound{"UNBOUNDED PRECEDING"}
+	// UnboundedFollowing the last row of partition
+	UnboundedFollowing = FrameBound{"UNBOUNDED FOLLOWING"}
+	// CurrentRow the current row
+	CurrentRow = FrameBound{"CURRENT ROW"}
+)
+
+// Preceding n rows (or values in Range) before the current row
+func Preceding(n uint) FrameBound { return FrameBound{fmt.Sprintf("%d PRECEDING", n)} }
+
+// Following n rows (or values in Range) after the current row
+func Following(n uint) FrameBound { return FrameBound{fmt.Sprintf("%d FOLLOWING", n)} }
+
+// over build window function call over window
+func over(function string, vars []interface{}, w Window) clause.Expr {
+	spec, windowVars := w.build()
+	return clause.Expr{SQL: function + " OVER (" + spec + ")", Vars: append(vars, windowVars...)}
+}
+
+// ======================== window function ========================
+
+// RowNumber number of the current row within its partition, eg:
+//
+//	field.RowNumber(field.PartitionBy(u.CompanyID).OrderBy(u.Salary.Desc())).As("rn")
+func RowNumber(w Window) Int {
+	return Int{expr{e: over("ROW_NUMBER()", nil, w)}}
+}
+
+// Rank rank of the current row within its partition, with gaps
+func Rank(w Window) Int {
+	return Int{expr{e: over("RANK()", nil, w)}}
+}
+
+// DenseRank rank of the current row within its partition, without gaps
+func DenseRank(w Window) Int {
+	return Int{expr{e: over("DENSE_RANK()", nil, w)}}
+}
+
+// Lag value of column from the row lagging the current row by offset within its partition
+func Lag(column Expr, offset uint, w Window) Field {
+	return Field{expr{e: over(fmt.Sprintf("LAG(?,%d)", offset), []interface{}{column.RawExpr()}, w)}}
+}
+
+// Lead value of column from the row leading the current row by offset within its partition
+func Lead(column Expr, offset uint, w Window) Field {
+	return Field{expr{e: over(fmt.Sprintf("LEAD(?,%d)", offset), []interface{}{column.RawExpr()}, w)}}
+}
+
+// Over compute aggregate over window, eg: u.Salary.Sum().Over(field.PartitionBy(u.CompanyID))
+func (e expr) Over(w Window) Field {
+	return Field{e.setE(over("?", []interface{}{e.RawExpr()}, w))}
+}
@@ -114,6 +114,33 @@ var (
 			return m
 		}
 	}
+	// FieldSoftDelete specify soft delete column, mode is gen.SoftDeleteFlag, gen.SoftDeleteUnix, gen.SoftDeleteMilli
+	// or gen.SoftDeleteNano for legacy schemas,
+	// gen.SoftDeleteDeletedAt will change field type to gorm.DeletedAt
+	FieldSoftDelete = func(columnName string, mode SoftDeleteMode) model.ModifyMemberOpt {
+		return func(m *model.Member) *model.Member {
+			if m.ColumnName != columnName {
+				return m
+			}
+			m.SoftDelete = true
+			if mode == SoftDeleteDeletedAt {
+				m.Type = "gorm.DeletedAt"
+				return m
+			}
+			m.GORMTag += ";softDelete:" + string(mode)
+			return m
+		}
+	}
+	// FieldVersion specify version column for optimistic lock
+	FieldVersion = func(columnName string) model.ModifyMemberOpt {
+		return func(m *model.Member) *model.Member {
+			if m.ColumnName == columnName {
+				m.Version = true
+				m.GORMTag += ";version"
+			}
+			return m
+		}
+	}
 	// FieldTrimPrefix trim column name's prefix
 	FieldTrimPrefix = func(prefix string) model.ModifyMemberOpt {
 		return func(m *model.Member) *model.Member {
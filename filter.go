@@ -0,0 +1,203 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// WhereIf add conditions only if cond is true, eg: u.WhereIf(req.Name != "", u.Name.Eq(req.Name))
+func (d *DO) WhereIf(cond bool, conds ...Condition) Dao {
+	if !cond {
+		return d
+	}
+	return d.Where(conds...)
+}
+
+// FilterOption option of FilterByExample
+type FilterOption func(*filterConfig)
+
+type filterConfig struct {
+	like bool
+	zero map[string]bool
+}
+
+// FilterLike match string fields without operator by LIKE %value% instead of equality
+func FilterLike() FilterOption {
+	return func(c *filterConfig) { c.like = true }
+}
+
+// FilterZero filter by zero value of fields, eg: FilterZero("Famous") for Famous = false, fields are named by struct field
+func FilterZero(fields ...string) FilterOption {
+	return func(c *filterConfig) {
+		for _, f := range fields {
+			c.zero[f] = true
+		}
+	}
+}
+
+// FilterByExample add conditions of non-zero fields of example, which is a model or a filter struct.
+// Pointer fields are used if they are not nil, slice fields are matched by IN.
+// Tag `filter` of field specify column and operator, eg:
+//
+//	type UserFilter struct {
+//		Name    string    `filter:"op:like"`
+//		MinAge  int       `filter:"column:age;op:gte"`
+//		MaxAge  int       `filter:"column:age;op:lte"`
+//		IDs     []uint    `filter:"column:id"`
+//		Famous  *bool
+//		Keyword string    `filter:"-"`
+//	}
+//
+//	u.FilterByExample(&UserFilter{Name: "modi", MinAge: 18, IDs: []uint{1, 2}}).Find()
+//
+// the above usage is equivalent to SQL statement:
+//
+//	SELECT * FROM `users` WHERE `users`.`name` LIKE "%modi%" AND `users`.`age` >= 18 AND `users`.`id` IN (1,2)
+//
+// operators are eq(default), neq, gt, gte, lt, lte, like(%value%), prefix(value%) and in
+func (d *DO) FilterByExample(example interface{}, opts ...FilterOption) Dao {
+	config := &filterConfig{zero: map[string]bool{}}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	value := reflect.ValueOf(example)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return d
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return d.withError(fmt.Errorf("FilterByExample: example must be struct, got %T", example))
+	}
+
+	exprs, err := d.filterExprs(value, config)
+	if err != nil {
+		return d.withError(err)
+	}
+	if len(exprs) == 0 {
+		return d
+	}
+	return d.getInstance(d.db.Clauses(clause.Where{Exprs: exprs}))
+}
+
+func (d *DO) filterExprs(value reflect.Value, config *filterConfig) (exprs []clause.Expression, err error) {
+	isModel := d.schema != nil && value.Type() == d.schema.ModelType
+	for i := 0; i < value.NumField(); i++ {
+		f, v := value.Type().Field(i), value.Field(i)
+		tag := f.Tag.Get("filter")
+		if tag == "-" || f.PkgPath != "" {
+			continue
+		}
+		if f.Anonymous && v.Kind() == reflect.Struct && tag == "" {
+			embedded, err := d.filterExprs(v, config)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, embedded...)
+			continue
+		}
+
+		// nil pointers and zero values are ignored, except zero values specified by FilterZero
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		} else if (v.IsZero() || v.Kind() == reflect.Slice && v.Len() == 0) && !config.zero[f.Name] {
+			continue
+		}
+
+		settings := schema.ParseTagSetting(tag, ";")
+		column := settings["COLUMN"]
+		if column == "" {
+			if d.schema != nil {
+				if field := d.schema.LookUpField(f.Name); field != nil && field.DBName != "" {
+					column = field.DBName
+				} else if isModel {
+					continue // relations and fields ignored by gorm
+				} else {
+					return nil, fmt.Errorf("FilterByExample: column of field %s not found, specify it by tag `filter:\"column:name\"`", f.Name)
+				}
+			} else {
+				column = d.db.NamingStrategy.ColumnName("", f.Name)
+			}
+		}
+
+		op := strings.ToLower(settings["OP"])
+		if op == "" {
+			switch {
+			case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+				op = "in"
+			case v.Kind() == reflect.String && config.like:
+				op = "like"
+			default:
+				op = "eq"
+			}
+		}
+
+		expr, err := filterExpr(clause.Column{Table: clause.CurrentTable, Name: column}, op, v)
+		if err != nil {
+			return nil, fmt.Errorf("FilterByExample: field %s: %w", f.Name, err)
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+func filterExpr(column clause.Column, op string, v reflect.Value) (clause.Expression, error) {
+	value := v.Interface()
+	switch op {
+	case "eq":
+		return clause.Eq{Column: column, Value: value}, nil
+	case "neq":
+		return clause.Neq{Column: column, Value: value}, nil
+	case "gt":
+		return clause.Gt{Column: column, Value: value}, nil
+	case "gte":
+		return clause.Gte{Column: column, Value: value}, nil
+	case "lt":
+		return clause.Lt{Column: column, Value: value}, nil
+	case "lte":
+		return clause.Lte{Column: column, Value: value}, nil
+	case "like":
+		return escapedLike{column: column, pattern: "%" + likeEscaper.Replace(fmt.Sprint(value)) + "%"}, nil
+	case "prefix":
+		return escapedLike{column: column, pattern: likeEscaper.Replace(fmt.Sprint(value)) + "%"}, nil
+	case "in":
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("operator in requires slice, got %s", v.Type())
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Interface()
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %q", op)
+}
+
+// likeEscaper escape metacharacters of LIKE with backslash, so that value is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likeEscapeDialects dialects without default escape character of LIKE, ESCAPE '\' is specified for them
+var likeEscapeDialects = map[string]bool{"sqlite": true, "sqlserver": true}
+
+// escapedLike LIKE condition whose pattern is escaped by likeEscaper
+type escapedLike struct {
+	column  clause.Column
+	pattern string
+}
+
+func (l escapedLike) Build(builder clause.Builder) {
+	clause.Like{Column: l.column, Value: l.pattern}.Build(builder)
+	if stmt, ok := builder.(*gorm.Statement); ok && likeEscapeDialects[stmt.Dialector.Name()] {
+		builder.WriteString(` ESCAPE '\'`)
+	}
+}
@@ -65,6 +65,7 @@ type Config struct {
 	ModelPkgPath      string // generated model code's package name
 	FieldNullable     bool
 	FieldWithIndexTag bool
+	TenantColumn      string // tenant id column, models with it are scoped by Query.WithTenant
 
 	Mode GenerateMode // generate mode
 
@@ -131,6 +132,8 @@ type Generator struct {
 	Config
 
 	Data map[string]*genInfo
+
+	projections []*check.BaseStruct
 }
 
 // UseDB set db connection
@@ -178,10 +181,18 @@ func (g *Generator) ApplyBasic(models ...interface{}) {
 // ApplyInterface specifies method interfaces on structures, implment codes will be generated after calling g.Execute()
 // eg: g.ApplyInterface(func(model.Method){}, model.User{}, model.Company{})
 func (g *Generator) ApplyInterface(fc interface{}, models ...interface{}) {
-	structs, err := check.CheckStructs(g.db, models...)
-	if err != nil {
-		g.db.Logger.Error(context.Background(), "check struct fail: %v", err)
-		panic("check struct fail")
+	var structs []*check.BaseStruct
+	for _, m := range models {
+		// structs failed to check are skipped, so models are checked one by one to keep sharding info of each
+		bases, err := check.CheckStructs(g.db, m)
+		if err != nil {
+			g.db.Logger.Error(context.Background(), "check struct fail: %v", err)
+			panic("check struct fail")
+		}
+		for _, st := range bases {
+			st.Sharded = st.Sharded || isSharder(m)
+		}
+		structs = append(structs, bases...)
 	}
 	g.apply(fc, structs)
 }
@@ -395,6 +406,23 @@ func (g *Generator) generateBaseStruct() (err error) {
 
 		g.successInfo(fmt.Sprintf("generate model file(table <%s> -> {%s.%s}): %s", data.TableName, data.StructInfo.Package, data.StructInfo.Type, modelFile))
 	}
+
+	for _, projection := range g.projections {
+		mkdir()
+
+		var buf bytes.Buffer
+		err = render(tmpl.ProjectionTemplate, &buf, projection)
+		if err != nil {
+			return err
+		}
+		projectionFile := fmt.Sprint(outPath, g.db.NamingStrategy.ColumnName("", projection.StructName), ".gen.go")
+		err = g.output(projectionFile, buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		g.successInfo(fmt.Sprintf("generate projection file({%s.%s}): %s", projection.StructInfo.Package, projection.StructName, projectionFile))
+	}
 	return nil
 }
 
@@ -417,6 +445,7 @@ func (g *Generator) output(fileName string, content []byte) error {
 func (g *Generator) pushBaseStruct(base *check.BaseStruct) (*genInfo, error) {
 	structName := base.StructName
 	if g.Data[structName] == nil {
+		g.markTenant(base)
 		g.Data[structName] = &genInfo{BaseStruct: base}
 	}
 	if g.Data[structName].Source != base.Source {
@@ -426,6 +455,18 @@ func (g *Generator) pushBaseStruct(base *check.BaseStruct) (*genInfo, error) {
 	return g.Data[structName], nil
 }
 
+// markTenant mark member of tenant column
+func (g *Generator) markTenant(base *check.BaseStruct) {
+	if g.TenantColumn == "" {
+		return
+	}
+	for _, m := range base.Members {
+		if m.ColumnName == g.TenantColumn && !m.IsRelation() {
+			m.Tenant = true
+		}
+	}
+}
+
 func outputFile(filename string, flag int, data []byte) error {
 	out, err := os.OpenFile(filename, flag, 0640)
 	if err != nil {
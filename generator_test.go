@@ -2,6 +2,7 @@ package gen
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -27,6 +28,24 @@ func TestConfig(t *testing.T) {
 	}
 }
 
+func TestGenerator_ApplyBasic(t *testing.T) {
+	// unexported struct is skipped, sharding info should be kept with its own struct
+	type unexportedRaw struct{ ID int64 }
+
+	g := NewGenerator(Config{OutPath: "query"})
+	g.UseDB(db)
+	g.ApplyBasic(unexportedRaw{}, PostRaw{}, OrderRaw{})
+
+	for name, sharded := range map[string]bool{"PostRaw": false, "OrderRaw": true} {
+		if data := g.Data[name]; data == nil || data.Sharded != sharded {
+			t.Errorf("%s expects sharded %t got %+v", name, sharded, data)
+		}
+	}
+	if len(g.Data) != 2 {
+		t.Errorf("unexported struct expects to be skipped, got %d structs", len(g.Data))
+	}
+}
+
 // test data
 type mysqlDialectors struct{ tests.DummyDialector }
 
@@ -36,6 +55,22 @@ func (mysqlDialectors) Name() string {
 
 var db, _ = gorm.Open(mysqlDialectors{}, nil)
 
+type sqliteDialectors struct{ tests.DummyDialector }
+
+func (sqliteDialectors) Name() string {
+	return "sqlite"
+}
+
+var sqliteDB, _ = gorm.Open(sqliteDialectors{}, &gorm.Config{DryRun: true})
+
+type postgresDialectors struct{ tests.DummyDialector }
+
+func (postgresDialectors) Name() string {
+	return "postgres"
+}
+
+var postgresDB, _ = gorm.Open(postgresDialectors{}, &gorm.Config{DryRun: true})
+
 func init() {
 	db = db.Debug()
 
@@ -43,6 +78,8 @@ func init() {
 		UpdateClauses: []string{"UPDATE", "SET", "WHERE", "ORDER BY", "LIMIT"},
 		DeleteClauses: []string{"DELETE", "FROM", "WHERE", "ORDER BY", "LIMIT"},
 	})
+	callbacks.RegisterDefaultCallbacks(sqliteDB, &callbacks.Config{})
+	callbacks.RegisterDefaultCallbacks(postgresDB, &callbacks.Config{})
 }
 
 // User user data struct
@@ -92,6 +129,13 @@ type user struct {
 	Address    field.String
 	Famous     field.Bool
 	RegisterAt field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (u user) GetFieldByName(fieldName string) (field.Expr, bool) {
+	field, ok := u.fieldMap[fieldName]
+	return field, ok
 }
 
 type userDo struct{ DO }
@@ -262,6 +306,10 @@ var u = func() *user {
 		Famous:     field.NewBool("", "famous"),
 		RegisterAt: field.NewTime("", "register_at"),
 	}
+	u.fieldMap = map[string]field.Expr{
+		"id": u.ID, "name": u.Name, "age": u.Age, "score": u.Score,
+		"address": u.Address, "famous": u.Famous, "register_at": u.RegisterAt,
+	}
 	u.UseDB(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}))
 	u.UseModel(User{})
 	return &u
@@ -304,3 +352,187 @@ var teacher = func() *Teacher {
 	t.UseModel(TeacherRaw{})
 	return &t
 }()
+
+// AccountRaw account data struct with custom soft delete flag column and version column
+type AccountRaw struct {
+	ID        int64 `gorm:"primary_key"`
+	Name      string
+	IsDeleted int   `gorm:"softDelete:flag"`
+	Version   int64 `gorm:"version"`
+	CreatedAt time.Time
+}
+
+func (AccountRaw) TableName() string {
+	return "account"
+}
+
+type Account struct {
+	DO
+
+	ID        field.Int64
+	Name      field.String
+	IsDeleted field.Int
+	Version   field.Int64
+}
+
+var account = func() *Account {
+	a := Account{
+		ID:        field.NewInt64("account", "id"),
+		Name:      field.NewString("account", "name"),
+		IsDeleted: field.NewInt("account", "is_deleted"),
+		Version:   field.NewInt64("account", "version"),
+	}
+	a.UseDB(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}))
+	a.UseModel(AccountRaw{})
+	return &a
+}()
+
+// MemberRaw member data struct scoped by tenant
+type MemberRaw struct {
+	ID       int64 `gorm:"primary_key"`
+	TenantID int64
+	Name     string
+}
+
+func (MemberRaw) TableName() string {
+	return "member"
+}
+
+type Member struct {
+	DO
+
+	ID       field.Int64
+	TenantID field.Int64
+	Name     field.String
+}
+
+var member = func() *Member {
+	m := Member{
+		ID:       field.NewInt64("member", "id"),
+		TenantID: field.NewInt64("member", "tenant_id"),
+		Name:     field.NewString("member", "name"),
+	}
+	m.UseDB(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}))
+	m.UseModel(MemberRaw{})
+	m.UseTenant("tenant_id")
+	return &m
+}()
+
+// EmployeeRaw employee data struct referring manager in the same table
+type EmployeeRaw struct {
+	ID        int64 `gorm:"primary_key"`
+	Name      string
+	ManagerID int64
+	DeletedAt gorm.DeletedAt
+}
+
+func (EmployeeRaw) TableName() string {
+	return "employee"
+}
+
+type Employee struct {
+	DO
+
+	ID        field.Int64
+	Name      field.String
+	ManagerID field.Int64
+}
+
+func (e Employee) As(alias string) *Employee {
+	e.UseAlias(alias)
+	return e.updateTableName(alias)
+}
+
+func (e *Employee) updateTableName(table string) *Employee {
+	e.ID = field.NewInt64(table, "id")
+	e.Name = field.NewString(table, "name")
+	e.ManagerID = field.NewInt64(table, "manager_id")
+	return e
+}
+
+var employee = func() *Employee {
+	e := Employee{}
+	e.UseDB(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}))
+	e.UseModel(EmployeeRaw{})
+	return e.updateTableName(e.TableName())
+}()
+
+// OrderRaw order data struct sharded by user id
+type OrderRaw struct {
+	ID     int64 `gorm:"primary_key"`
+	UserID int64
+	Amount float64
+}
+
+func (OrderRaw) TableName() string {
+	return "orders"
+}
+
+func (OrderRaw) Sharding() Sharding {
+	return Sharding{
+		Column:   "user_id",
+		Suffix:   func(key interface{}) (string, error) { return fmt.Sprintf("_%02d", key.(int64)%4), nil },
+		Suffixes: []string{"_00", "_01", "_02", "_03"},
+	}
+}
+
+type Order struct {
+	DO
+
+	ID     field.Int64
+	UserID field.Int64
+	Amount field.Float64
+}
+
+var order = func() *Order {
+	o := Order{
+		ID:     field.NewInt64("orders", "id"),
+		UserID: field.NewInt64("orders", "user_id"),
+		Amount: field.NewFloat64("orders", "amount"),
+	}
+	o.UseDB(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}))
+	o.UseModel(OrderRaw{})
+	return &o
+}()
+
+// enrollRaw enroll data struct with composite primary key
+type enrollRaw struct {
+	StudentID int64 `gorm:"primaryKey"`
+	CourseID  int64 `gorm:"primaryKey"`
+	Score     int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PostRaw post data struct with nullable column
+type PostRaw struct {
+	ID          int64 `gorm:"primary_key"`
+	Title       string
+	Score       int
+	PublishedAt *time.Time
+}
+
+func (PostRaw) TableName() string {
+	return "post"
+}
+
+type Post struct {
+	DO
+
+	ID          field.Int64
+	Title       field.String
+	Score       field.Int
+	PublishedAt field.Time
+}
+
+var post = func() *Post {
+	p := Post{
+		ID:          field.NewInt64("post", "id"),
+		Title:       field.NewString("post", "title"),
+		Score:       field.NewInt("post", "score"),
+		PublishedAt: field.NewTime("post", "published_at"),
+	}
+	p.UseDB(db.Session(&gorm.Session{Context: context.Background(), DryRun: true}))
+	p.UseModel(PostRaw{})
+	return &p
+}()
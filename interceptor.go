@@ -0,0 +1,125 @@
+package gen
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// interceptorsKey db setting key of interceptors
+	interceptorsKey = "gen:interceptors"
+	// interceptingKey db setting key marks finisher is running in interceptors, nested finishers are not intercepted
+	interceptingKey = "gen:intercepting"
+)
+
+// Invocation finisher call seen by interceptors, SQL is rendered before interceptors are called except finishers
+// whose rows are consumed by the caller (Rows, ScanRows, Iter), RowsAffected and Duration are filled after next returns
+type Invocation struct {
+	Table    string // table name of model
+	Finisher string // finisher name, eg: Find, Update
+
+	SQL          string // rendered sql of the last statement, updated with the executed one after next returns
+	RowsAffected int64  // rows affected (or returned) by all statements
+	Duration     time.Duration
+}
+
+// Interceptor intercept finisher calls of DO, call next to run the finisher and return its error,
+// or return without calling next to short-circuit, eg:
+//
+//	func(ctx context.Context, inv *gen.Invocation, next func() error) error {
+//		err := next()
+//		metrics.Observe(inv.Table, inv.Finisher, inv.Duration, err)
+//		return err
+//	}
+type Interceptor func(ctx context.Context, inv *Invocation, next func() error) error
+
+// WithInterceptors return db whose finishers are intercepted by interceptors in order,
+// No one should use it directly in project, use query.Use(gen.WithInterceptors(db, interceptors...)) instead
+func WithInterceptors(db *gorm.DB, interceptors ...Interceptor) *gorm.DB {
+	return db.Set(interceptorsKey, appendInterceptors(db, interceptors)).Session(new(gorm.Session))
+}
+
+// UseInterceptors return a copy of DO whose finishers are intercepted, interceptors run after those of Query,
+// they are kept by DO so that db replaced by transactions is intercepted too
+func (d *DO) UseInterceptors(interceptors ...Interceptor) Dao {
+	d = d.getInstance(d.db)
+	// copy on write, interceptors are shared by copies of DO
+	d.interceptors = append(append(make([]Interceptor, 0, len(d.interceptors)+len(interceptors)), d.interceptors...), interceptors...)
+	return d
+}
+
+func appendInterceptors(db *gorm.DB, interceptors []Interceptor) []Interceptor {
+	value, _ := db.Get(interceptorsKey)
+	current, _ := value.([]Interceptor)
+	// copy on write, interceptors are shared by sessions of db
+	return append(append(make([]Interceptor, 0, len(current)+len(interceptors)), current...), interceptors...)
+}
+
+// intercept run finisher call through interceptors, call is invoked with DO whose statements are traced into
+// invocation, intercepted is false if there is no interceptor or finisher is already intercepted.
+// render is run in dry run to render SQL before interceptors decide, it must not write results or consume
+// arguments of the caller, nil to skip rendering, eg: rows consumed by the caller
+func (d *DO) intercept(finisher string, call, render func(d *DO) error) (intercepted bool, err error) {
+	if intercepting, _ := d.db.Get(interceptingKey); intercepting == true {
+		return false, nil
+	}
+	interceptors := appendInterceptors(d.db, d.interceptors)
+	if len(interceptors) == 0 {
+		return false, nil
+	}
+
+	inv := &Invocation{Table: d.TableName(), Finisher: finisher}
+	if render != nil {
+		// render statement in dry run before interceptors decide, hooks are skipped as finisher is run again by next
+		dry := d.getInstance(d.db.Session(&gorm.Session{SkipHooks: true}))
+		if stmt, _ := dry.ToSQL(func(tx Dao) error { return render(tx.(*DO)) }); stmt.SQL != "" {
+			inv.SQL = stmt.Interpolated
+		}
+	}
+	db := d.db.Session(&gorm.Session{Logger: &traceLogger{Interface: d.db.Logger, inv: inv}})
+	traced := d.getInstance(db.Set(interceptingKey, true).Session(new(gorm.Session)))
+
+	ctx := d.stmtContext()
+	next := func() error {
+		begin := time.Now()
+		err := call(traced)
+		inv.Duration = time.Since(begin)
+		return err
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, proceed := interceptors[i], next
+		next = func() error { return interceptor(ctx, inv, proceed) }
+	}
+	return true, next()
+}
+
+// orEmptySlice return empty slice of model if results is nil, eg: finisher is short-circuited by interceptor
+func (d *DO) orEmptySlice(results interface{}) interface{} {
+	if results == nil && d.model != nil {
+		return reflect.Indirect(reflect.ValueOf(d.newResultSlicePointer())).Interface()
+	}
+	return results
+}
+
+// traceLogger logger traces statements into invocation, it is installed on intercepted finishers only
+type traceLogger struct {
+	logger.Interface
+	inv *Invocation
+}
+
+func (l *traceLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &traceLogger{Interface: l.Interface.LogMode(level), inv: l.inv}
+}
+
+func (l *traceLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	sql, rows := fc()
+	l.inv.SQL = sql
+	if rows > 0 {
+		l.inv.RowsAffected += rows
+	}
+	l.Interface.Trace(ctx, begin, func() (string, int64) { return sql, rows }, err)
+}
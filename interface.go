@@ -2,6 +2,7 @@ package gen
 
 import (
 	"database/sql"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
@@ -37,12 +38,16 @@ type Dao interface {
 	subQuery
 
 	As(alias string) Dao
+	Alias() string
+	TableName() string
 
 	Not(conds ...Condition) Dao
 	Or(conds ...Condition) Dao
 
 	Select(columns ...field.Expr) Dao
 	Where(conds ...Condition) Dao
+	WhereIf(cond bool, conds ...Condition) Dao
+	FilterByExample(example interface{}, opts ...FilterOption) Dao
 	Order(columns ...field.Expr) Dao
 	Distinct(columns ...field.Expr) Dao
 	Omit(columns ...field.Expr) Dao
@@ -54,7 +59,27 @@ type Dao interface {
 	Limit(limit int) Dao
 	Offset(offset int) Dao
 	Scopes(funcs ...func(Dao) Dao) Dao
+	Union(others ...Dao) Dao
+	UnionAll(others ...Dao) Dao
+	With(ctes ...*CTE) Dao
 	Unscoped() Dao
+	WithDeleted() Dao
+	OnlyDeleted() Dao
+	WithVersion(version interface{}) Dao
+	WithTenant(tenant interface{}) Dao
+	UnscopedTenant() Dao
+	UsePrimary() Dao
+	Cache(ttl time.Duration) Dao
+	UseInterceptors(interceptors ...Interceptor) Dao
+	ForUpdate() Dao
+	ForShare() Dao
+	SkipLocked() Dao
+	NoWait() Dao
+	Of(tables ...schema.Tabler) Dao
+	FanOut() Dao
+	OnConflict(columns ...field.Expr) Dao
+	DoUpdate(columns ...field.AssignExpr) Dao
+	DoNothing() Dao
 	Attrs(attrs ...field.AssignExpr) Dao
 	Assign(attrs ...field.AssignExpr) Dao
 	Joins(field field.RelationField) Dao
@@ -69,6 +94,8 @@ type Dao interface {
 	Find() (results interface{}, err error)
 	FindInBatch(batchSize int, fc func(tx Dao, batch int) error) (results interface{}, err error)
 	FindInBatches(dest interface{}, batchSize int, fc func(tx Dao, batch int) error) error
+	FindByCursor(cursor string, limit int, columns ...field.Expr) (results interface{}, page CursorPage, err error)
+	Iter() (*Iterator, error)
 	FirstOrInit() (result interface{}, err error)
 	FirstOrCreate() (result interface{}, err error)
 	Update(column field.Expr, value interface{}) (info resultInfo, err error)
@@ -77,11 +104,35 @@ type Dao interface {
 	UpdateColumn(column field.Expr, value interface{}) (info resultInfo, err error)
 	UpdateColumns(values interface{}) (info resultInfo, err error)
 	UpdateColumnSimple(columns ...field.AssignExpr) (info resultInfo, err error)
+	UpdateBatch(values interface{}, batchSize int, columns ...field.Expr) (info resultInfo, err error)
 	Delete() (info resultInfo, err error)
+	ForceDelete() (info resultInfo, err error)
+	Restore() (info resultInfo, err error)
 	Count() (int64, error)
 	Row() *sql.Row
 	Rows() (*sql.Rows, error)
 	Scan(dest interface{}) error
+	ScanInto(dest interface{}) error
 	Pluck(column field.Expr, dest interface{}) error
 	ScanRows(rows *sql.Rows, dest interface{}) error
+	PluckString(column field.String) ([]string, error)
+	PluckInt(column field.Int) ([]int, error)
+	PluckInt32(column field.Int32) ([]int32, error)
+	PluckInt64(column field.Int64) ([]int64, error)
+	PluckUint(column field.Uint) ([]uint, error)
+	PluckFloat64(column field.Float64) ([]float64, error)
+	PluckBool(column field.Bool) ([]bool, error)
+	PluckTime(column field.Time) ([]time.Time, error)
+	ScanString(column field.String) (string, error)
+	ScanInt(column field.Int) (int, error)
+	ScanInt32(column field.Int32) (int32, error)
+	ScanInt64(column field.Int64) (int64, error)
+	ScanUint(column field.Uint) (uint, error)
+	ScanFloat64(column field.Float64) (float64, error)
+	ScanBool(column field.Bool) (bool, error)
+	ScanTime(column field.Time) (time.Time, error)
+	ScanOne(column field.Expr, dest interface{}) error
+	ToSQL(finisher func(tx Dao) error) (SQLStatement, error)
+	Explain() (*ExplainPlan, error)
+	ExplainAnalyze() (*ExplainPlan, error)
 }
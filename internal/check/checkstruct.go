@@ -25,6 +25,7 @@ type BaseStruct struct {
 	StructInfo    parser.Param
 	Members       []*model.Member
 	Source        model.SourceCode
+	Sharded       bool // whether model is stored in sharded tables
 }
 
 // parseStruct get all elements of struct with gorm's Parse, ignore unexported elements
@@ -37,10 +38,15 @@ func (b *BaseStruct) parseStruct(st interface{}) error {
 	b.TableName = stmt.Table
 
 	for _, f := range stmt.Schema.Fields {
+		if err := checkSoftDeleteTag(f.TagSettings); err != nil {
+			return fmt.Errorf("field %s of %s: %w", f.Name, b.StructName, err)
+		}
 		b.appendOrUpdateMember((&model.Member{
 			Name:       f.Name,
 			Type:       b.getMemberRealType(f.FieldType),
 			ColumnName: f.DBName,
+			SoftDelete: isSoftDeleteField(f),
+			Version:    isVersionField(f),
 		}))
 	}
 	for _, r := range ParseStructRelationShip(&stmt.Schema.Relationships) {
@@ -95,6 +101,36 @@ func (b *BaseStruct) appendMember(member *model.Member) {
 // HasMember check if BaseStruct has members
 func (b *BaseStruct) HasMember() bool { return len(b.Members) > 0 }
 
+// HasVersion check if BaseStruct has version member for optimistic lock
+func (b *BaseStruct) HasVersion() bool {
+	for _, m := range b.Members {
+		if m.Version {
+			return true
+		}
+	}
+	return false
+}
+
+// TenantColumn return column name of tenant member, empty if BaseStruct is not tenant scoped
+func (b *BaseStruct) TenantColumn() string {
+	for _, m := range b.Members {
+		if m.Tenant {
+			return m.ColumnName
+		}
+	}
+	return ""
+}
+
+// HasSoftDelete check if BaseStruct has soft delete member
+func (b *BaseStruct) HasSoftDelete() bool {
+	for _, m := range b.Members {
+		if m.SoftDelete {
+			return true
+		}
+	}
+	return false
+}
+
 // check if struct is exportable and if struct in main package and if member's type is regular
 func (b *BaseStruct) check() (err error) {
 	if b.StructInfo.InMainPkg() {
@@ -117,6 +153,38 @@ func (b *BaseStruct) Relations() []field.Relation {
 	return result
 }
 
+// isSoftDeleteField gorm.DeletedAt field, field with delete clauses (eg: soft_delete.DeletedAt of gorm.io/plugin/soft_delete)
+// or custom soft delete field tagged with `softDelete`
+func isSoftDeleteField(f *schema.Field) bool {
+	if f.IndirectFieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+		return true
+	}
+	if _, ok := reflect.New(f.IndirectFieldType).Interface().(schema.DeleteClausesInterface); ok {
+		return true
+	}
+	_, ok := f.TagSettings["SOFTDELETE"]
+	return ok
+}
+
+// checkSoftDeleteTag check value of `softDelete` tag, which is flag, unix, milli, nano or empty(unix)
+func checkSoftDeleteTag(settings map[string]string) error {
+	value, ok := settings["SOFTDELETE"]
+	if !ok {
+		return nil
+	}
+	switch strings.ToLower(value) {
+	case "softdelete", "flag", "unix", "milli", "nano":
+		return nil
+	}
+	return fmt.Errorf("unknown softDelete tag %q, should be flag, unix, milli or nano", value)
+}
+
+// isVersionField field tagged with `version` for optimistic lock
+func isVersionField(f *schema.Field) bool {
+	_, ok := f.TagSettings["VERSION"]
+	return ok
+}
+
 func GetStructNames(bases []*BaseStruct) (res []string) {
 	for _, base := range bases {
 		res = append(res, base.StructName)
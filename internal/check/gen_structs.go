@@ -65,6 +65,9 @@ func GenBaseStructs(db *gorm.DB, conf model.DBConf) (bases *BaseStruct, err erro
 		}
 
 		m = modifyMember(m, modifyOpts)
+		if err = checkSoftDeleteTag(schema.ParseTagSetting(m.GORMTag, ";")); err != nil {
+			return nil, fmt.Errorf("column %s of table %s: %w", m.ColumnName, tableName, err)
+		}
 		if ns, ok := db.NamingStrategy.(schema.NamingStrategy); ok {
 			ns.SingularTable = true
 			m.Name = ns.SchemaName(m.Name)
@@ -97,6 +100,28 @@ func GenBaseStructs(db *gorm.DB, conf model.DBConf) (bases *BaseStruct, err erro
 	return &base, nil
 }
 
+// GenProjectionStruct generate struct of selected columns
+func GenProjectionStruct(structName, modelPkg string, members []*model.Member) (*BaseStruct, error) {
+	if structName == "" {
+		return nil, fmt.Errorf("projection name is empty")
+	}
+	if err := checkModelName(structName); err != nil {
+		return nil, fmt.Errorf("projection name %q is invalid: %w", structName, err)
+	}
+	if modelPkg == "" {
+		modelPkg = DefaultModelPkg
+	}
+
+	return &BaseStruct{
+		GenBaseStruct: true,
+		StructName:    structName,
+		NewStructName: uncaptialize(structName),
+		S:             strings.ToLower(structName[0:1]),
+		StructInfo:    parser.Param{Type: structName, Package: filepath.Base(modelPkg)},
+		Members:       members,
+	}, nil
+}
+
 func filterMember(m *model.Member, opts []model.MemberOpt) *model.Member {
 	for _, opt := range opts {
 		if opt.Self()(m) == nil {
@@ -40,15 +40,23 @@ const (
 
 var keywords = []string{
 	"UnderlyingDB", "UseDB", "UseModel", "UseTable", "Quote", "Debug", "TableName", "WithContext",
-	"As", "Not", "Or", "Build", "Columns", "Hints",
+	"As", "Alias", "Not", "Or", "Build", "Columns", "Hints",
 	"Distinct", "Omit",
-	"Select", "Where", "Order", "Group", "Having", "Limit", "Offset",
+	"Select", "Where", "WhereIf", "FilterByExample", "Order", "Group", "Having", "Limit", "Offset",
 	"Join", "LeftJoin", "RightJoin",
 	"Save", "Create", "CreateInBatches",
-	"Update", "Updates", "UpdateColumn", "UpdateColumns",
+	"Update", "Updates", "UpdateColumn", "UpdateColumns", "UpdateBatch",
 	"Find", "FindInBatches", "First", "Take", "Last", "Pluck", "Count",
-	"Scan", "ScanRows", "Row", "Rows",
-	"Delete", "Unscoped",
+	"Scan", "ScanInto", "ScanRows", "Row", "Rows",
+	"PluckString", "PluckInt", "PluckInt32", "PluckInt64", "PluckUint", "PluckFloat64", "PluckBool", "PluckTime",
+	"ScanString", "ScanInt", "ScanInt32", "ScanInt64", "ScanUint", "ScanFloat64", "ScanBool", "ScanTime", "ScanOne",
+	"Delete", "Unscoped", "WithDeleted", "OnlyDeleted", "Restore", "ForceDelete",
+	"WithVersion", "WithTenant", "UnscopedTenant", "UsePrimary", "FanOut",
+	"OnConflict", "DoUpdate", "DoNothing",
+	"FindByCursor", "Iter", "Cache", "UseInterceptors",
+	"ToSQL", "Explain", "ExplainAnalyze",
+	"ForUpdate", "ForShare", "SkipLocked", "NoWait", "Of",
+	"Union", "UnionAll", "With",
 	"Scopes",
 }
 
@@ -114,6 +122,9 @@ type Member struct {
 	GORMTag          string
 	NewTag           string
 	OverwriteTag     string
+	SoftDelete       bool
+	Version          bool
+	Tenant           bool
 
 	Relation *field.Relation
 }
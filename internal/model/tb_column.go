@@ -30,8 +30,9 @@ func (c *Column) AutoIncrement() bool {
 
 func (c *Column) ToMember(nullable bool) *Member {
 	memberType := dataType.Get(c.DataType, c.ColumnType)
+	softDelete := false
 	if c.ColumnName == "deleted_at" && memberType == "time.Time" {
-		memberType = "gorm.DeletedAt"
+		memberType, softDelete = "gorm.DeletedAt", true
 	}
 	if nullable && c.IsNullable == "YES" {
 		memberType = "*" + memberType
@@ -44,6 +45,7 @@ func (c *Column) ToMember(nullable bool) *Member {
 		MultilineComment: c.multilineComment(),
 		GORMTag:          c.buildGormTag(),
 		JSONTag:          c.ColumnName,
+		SoftDelete:       softDelete,
 	}
 }
 
@@ -49,6 +49,16 @@ func ({{.S}} {{.NewStructName}}Do) Where(conds ...gen.Condition) *{{.NewStructNa
 	return {{.S}}.withDO({{.S}}.DO.Where(conds...))
 }
 
+// WhereIf add conditions only if cond is true
+func ({{.S}} {{.NewStructName}}Do) WhereIf(cond bool, conds ...gen.Condition) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.WhereIf(cond, conds...))
+}
+
+// FilterByExample add conditions of non-zero fields of example, which is *{{.StructInfo.Package}}.{{.StructInfo.Type}} or a filter struct tagged by filter
+func ({{.S}} {{.NewStructName}}Do) FilterByExample(example interface{}, opts ...gen.FilterOption) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.FilterByExample(example, opts...))
+}
+
 func ({{.S}} {{.NewStructName}}Do) Order(conds ...field.Expr) *{{.NewStructName}}Do {
 	return {{.S}}.withDO({{.S}}.DO.Order(conds...))
 }
@@ -97,6 +107,113 @@ func ({{.S}} {{.NewStructName}}Do) Unscoped() *{{.NewStructName}}Do {
 	return {{.S}}.withDO({{.S}}.DO.Unscoped())
 }
 
+// OnConflict specify conflict target columns of upsert, must be followed by DoUpdate or DoNothing
+func ({{.S}} {{.NewStructName}}Do) OnConflict(columns ...field.Expr) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.OnConflict(columns...))
+}
+
+// DoUpdate update columns of the conflicted row, eg: DoUpdate(u.Name, u.Counter.Add(1))
+func ({{.S}} {{.NewStructName}}Do) DoUpdate(columns ...field.AssignExpr) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.DoUpdate(columns...))
+}
+
+// DoNothing ignore the conflicted row
+func ({{.S}} {{.NewStructName}}Do) DoNothing() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.DoNothing())
+}
+
+// UsePrimary force read finishers onto primary, eg: read your writes
+func ({{.S}} {{.NewStructName}}Do) UsePrimary() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.UsePrimary())
+}
+
+// Union combine results with other queries and remove duplicate rows
+func ({{.S}} {{.NewStructName}}Do) Union(others ...*{{.NewStructName}}Do) *{{.NewStructName}}Do {
+	daos := make([]gen.Dao, len(others))
+	for i, other := range others {
+		daos[i] = &other.DO
+	}
+	return {{.S}}.withDO({{.S}}.DO.Union(daos...))
+}
+
+// UnionAll combine results with other queries and keep duplicate rows
+func ({{.S}} {{.NewStructName}}Do) UnionAll(others ...*{{.NewStructName}}Do) *{{.NewStructName}}Do {
+	daos := make([]gen.Dao, len(others))
+	for i, other := range others {
+		daos[i] = &other.DO
+	}
+	return {{.S}}.withDO({{.S}}.DO.UnionAll(daos...))
+}
+
+// With prefix statement with common table expressions, CTEs used in Join are prefixed automatically
+func ({{.S}} {{.NewStructName}}Do) With(ctes ...*gen.CTE) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.With(ctes...))
+}
+
+// ForUpdate lock selected rows for update until the transaction ends
+func ({{.S}} {{.NewStructName}}Do) ForUpdate() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.ForUpdate())
+}
+
+// ForShare lock selected rows in share mode until the transaction ends
+func ({{.S}} {{.NewStructName}}Do) ForShare() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.ForShare())
+}
+
+// SkipLocked skip rows locked by other transactions, must follow ForUpdate or ForShare
+func ({{.S}} {{.NewStructName}}Do) SkipLocked() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.SkipLocked())
+}
+
+// NoWait fail immediately if rows are locked by other transactions, must follow ForUpdate or ForShare
+func ({{.S}} {{.NewStructName}}Do) NoWait() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.NoWait())
+}
+
+// Of lock rows of tables only, must follow ForUpdate or ForShare
+func ({{.S}} {{.NewStructName}}Do) Of(tables ...schema.Tabler) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.Of(tables...))
+}
+
+// Cache cache results of First, Take, Find and Count in ttl, cached results are invalidated by writes on the table
+func ({{.S}} {{.NewStructName}}Do) Cache(ttl time.Duration) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.Cache(ttl))
+}
+
+// UseInterceptors intercept finishers of the query, interceptors run after those of Query
+func ({{.S}} {{.NewStructName}}Do) UseInterceptors(interceptors ...gen.Interceptor) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.UseInterceptors(interceptors...))
+}
+
+{{if .HasSoftDelete}}
+func ({{.S}} {{.NewStructName}}Do) WithDeleted() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.WithDeleted())
+}
+
+func ({{.S}} {{.NewStructName}}Do) OnlyDeleted() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.OnlyDeleted())
+}
+{{end}}{{if .HasVersion}}
+// WithVersion specify the expected version, update finishers return gen.ErrOptimisticLock if no row matched with it
+func ({{.S}} {{.NewStructName}}Do) WithVersion(version interface{}) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.WithVersion(version))
+}
+{{end}}{{if .Sharded}}
+// FanOut allow Find and Count without sharding key to query all shards and merge results
+func ({{.S}} {{.NewStructName}}Do) FanOut() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.FanOut())
+}
+{{end}}{{if .TenantColumn}}
+// WithTenant scope finishers with tenant, tenant is tenant id or context.Context carrying it(see gen.ContextWithTenant)
+func ({{.S}} {{.NewStructName}}Do) WithTenant(tenant interface{}) *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.WithTenant(tenant))
+}
+
+// UnscopedTenant disable tenant scope, which is required to access data of other tenants
+func ({{.S}} {{.NewStructName}}Do) UnscopedTenant() *{{.NewStructName}}Do {
+	return {{.S}}.withDO({{.S}}.DO.UnscopedTenant())
+}
+{{end}}
 func ({{.S}} {{.NewStructName}}Do) Create(values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
 	if len(values) == 0 {
 		return nil
@@ -108,8 +225,16 @@ func ({{.S}} {{.NewStructName}}Do) CreateInBatches(values []*{{.StructInfo.Packa
 	return {{.S}}.DO.CreateInBatches(values, batchSize)
 }
 
+// UpdateBatch update columns of values to their own values in one statement per batch, values are matched by primary keys,
+// columns maintained by gen or GORM(create time, soft delete, tenant, version) are skipped if no column is specified
+func ({{.S}} {{.NewStructName}}Do) UpdateBatch(values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int, columns ...field.Expr) (rowsAffected int64, err error) {
+	info, err := {{.S}}.DO.UpdateBatch(values, batchSize, columns...)
+	return info.RowsAffected, err
+}
+
 // Save : !!! underlying implementation is different with GORM
-// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values){{if .HasVersion}}
+// values with primary key are updated with optimistic lock, gen.ErrOptimisticLock is returned if the version is out of date{{end}}
 func ({{.S}} {{.NewStructName}}Do) Save(values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
 	if len(values) == 0 {
 		return nil
@@ -197,6 +322,36 @@ func ({{.S}} {{.NewStructName}}Do) FindByPage(offset int, limit int) (result []*
 	return
 }
 
+// FindByCursor find a page of records after cursor in order of columns(keyset pagination), cursor is empty for the first page,
+// columns should identify a record uniquely, eg: FindByCursor(cursor, 20, u.CreatedAt.Desc(), u.ID.Desc())
+func ({{.S}} {{.NewStructName}}Do) FindByCursor(cursor string, limit int, columns ...field.Expr) (result []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, page gen.CursorPage, err error) {
+	if results, page, err := {{.S}}.DO.FindByCursor(cursor, limit, columns...); err != nil {
+		return nil, page, err
+	} else {
+		return results.([]*{{.StructInfo.Package}}.{{.StructInfo.Type}}), page, nil
+	}
+}
+
+// Iter return iterator of query results, rows are scanned one at a time, the iterator must be closed after iteration
+func ({{.S}} {{.NewStructName}}Do) Iter() (*{{.NewStructName}}Iterator, error) {
+	it, err := {{.S}}.DO.Iter()
+	if err != nil {
+		return nil, err
+	}
+	return &{{.NewStructName}}Iterator{it}, nil
+}
+
+// {{.NewStructName}}Iterator iterator of {{.StructInfo.Type}} query results
+type {{.NewStructName}}Iterator struct{ *gen.Iterator }
+
+// Value return record of current row
+func (it {{.NewStructName}}Iterator) Value() *{{.StructInfo.Package}}.{{.StructInfo.Type}} {
+	if value, ok := it.Iterator.Value().(*{{.StructInfo.Package}}.{{.StructInfo.Type}}); ok {
+		return value
+	}
+	return nil
+}
+
 func ({{.S}} *{{.NewStructName}}Do) withDO(do gen.Dao) (*{{.NewStructName}}Do) {
 	{{.S}}.DO = *do.(*gen.DO)
 	return {{.S}}
@@ -26,3 +26,17 @@ func (*{{.StructName}}) TableName() string {
     return TableName{{.StructName}}
 }
 `
+
+// ProjectionTemplate struct of selected columns, it is the destination of Scan and ScanInto
+const ProjectionTemplate = NotEditMark + `
+package {{.StructInfo.Package}}
+
+import "time"
+
+// {{.StructName}} projection of selected columns
+type {{.StructName}} struct {
+    {{range .Members}}
+    {{.Name}} {{.Type}} ` + "`gorm:\"{{.GORMTag}}\" json:\"{{.JSONTag}}\"`" + `
+    {{- end}}
+}
+`
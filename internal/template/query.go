@@ -8,8 +8,8 @@ var (
 	{{end -}}
 )
 
-func SetDefault(db *gorm.DB) {
-	*Q = *Use(db)
+func SetDefault(db *gorm.DB, replicas ...*gorm.DB) {
+	*Q = *Use(db, replicas...)
 	{{range $name,$d :=.Data -}}
 	{{$d.StructName}} = &Q.{{$d.StructName}}
 	{{end -}}
@@ -18,7 +18,9 @@ func SetDefault(db *gorm.DB) {
 `
 
 const QueryTmpl = `
-func Use(db *gorm.DB) *Query {
+// Use build Query with primary db, read finishers are routed to replicas if specified
+func Use(db *gorm.DB, replicas ...*gorm.DB) *Query {
+	db = gen.WithReplicas(db, replicas...)
 	return &Query{
 		db: db,
 		{{range $name,$d :=.Data -}}
@@ -60,10 +62,32 @@ func (q *Query) WithContext(ctx context.Context) *queryCtx  {
 	}
 }
 
+{{if .TenantColumn}}
+// WithTenant scope all tenant models with tenant, tenant is tenant id or context.Context carrying it(see gen.ContextWithTenant)
+func (q *Query) WithTenant(tenant interface{}) *Query {
+	return q.clone(gen.WithTenant(q.db, tenant))
+}
+
+// UnscopedTenant disable tenant scope of all tenant models
+func (q *Query) UnscopedTenant() *Query {
+	return q.clone(gen.UnscopedTenant(q.db))
+}
+{{end}}
+// UsePrimary force read finishers onto primary, eg: read your writes
+func (q *Query) UsePrimary() *Query {
+	return q.clone(gen.UsePrimary(q.db))
+}
+
 func (q *Query) Transaction(fc func(tx *Query) error, opts ...*sql.TxOptions) error {
 	return q.db.Transaction(func(tx *gorm.DB) error { return fc(q.clone(tx)) }, opts...)
 }
 
+// TransactionWithRetry run fc in transaction, which is run again on deadlocks or serialization failures
+// according to policy until ctx is done, fc may be called more than once, side effects out of database should be idempotent
+func (q *Query) TransactionWithRetry(ctx context.Context, fc func(tx *Query) error, policy gen.RetryPolicy, opts ...*sql.TxOptions) error {
+	return gen.TransactionWithRetry(q.db.WithContext(ctx), func(tx *gorm.DB) error { return fc(q.clone(tx)) }, policy, opts...)
+}
+
 func (q *Query) Begin(opts ...*sql.TxOptions) *QueryTx {
 	return &QueryTx{q.clone(q.db.Begin(opts...))}
 }
@@ -7,7 +7,7 @@ const (
 		` + members + `
 	}
 	
-	` + getFieldMethod + cloneMethod + relationship + defineMethodStruct
+	` + asMethod + getFieldMethod + cloneMethod + relationship + defineMethodStruct
 
 	BaseStructWithContext = createMethod + `
 	type {{.NewStructName}} struct {
@@ -18,8 +18,10 @@ const (
 	func ({{.S}} *{{.NewStructName}}) WithContext(ctx context.Context) *{{.NewStructName}}Do { return {{.S}}.{{.NewStructName}}Do.WithContext(ctx)}
 
 	func ({{.S}} {{.NewStructName}}) TableName() string { return {{.S}}.{{.NewStructName}}Do.TableName()} 
+
+	func ({{.S}} {{.NewStructName}}) Alias() string { return {{.S}}.{{.NewStructName}}Do.Alias()}
 	
-	` + getFieldMethod + cloneMethod + relationship + defineMethodStruct
+	` + asMethod + getFieldMethod + cloneMethod + relationship + defineMethodStruct
 )
 
 const (
@@ -28,14 +30,12 @@ const (
 		_{{.NewStructName}} := {{.NewStructName}}{}
 	
 		_{{.NewStructName}}.{{.NewStructName}}Do.UseDB(db)
-		_{{.NewStructName}}.{{.NewStructName}}Do.UseModel(&{{.StructInfo.Package}}.{{.StructInfo.Type}}{})
+		_{{.NewStructName}}.{{.NewStructName}}Do.UseModel(&{{.StructInfo.Package}}.{{.StructInfo.Type}}{}){{if .TenantColumn}}
+		_{{.NewStructName}}.{{.NewStructName}}Do.UseTenant("{{.TenantColumn}}"){{end}}
 	
-		{{if .HasMember}}tableName := _{{.NewStructName}}.{{.NewStructName}}Do.TableName(){{end}}
-		_{{$.NewStructName}}.ALL = field.NewField(tableName, "*")
+		_{{$.NewStructName}}.updateTableName(_{{.NewStructName}}.{{.NewStructName}}Do.TableName())
 		{{range .Members -}}
-		{{if not .IsRelation -}}
-			_{{$.NewStructName}}.{{.Name}} = field.New{{.GenType}}(tableName, "{{.ColumnName}}")
-		{{- else -}}
+		{{if .IsRelation -}}
 			_{{$.NewStructName}}.{{.Relation.Name}} = {{$.NewStructName}}{{.Relation.RelationshipName}}{{.Relation.Name}}{
 				db: db.Session(&gorm.Session{}),
 
@@ -43,16 +43,36 @@ const (
 			}
 		{{end}}
 		{{end}}
-
-		_{{$.NewStructName}}.fieldMap = make(map[string]field.Expr, {{len .Members}})
-		{{range .Members -}}
-		{{if not .IsRelation -}}
-			_{{$.NewStructName}}.fieldMap["{{.ColumnName}}"] = _{{$.NewStructName}}.{{.Name}}
-		{{end -}}
-		{{end}}
 		return _{{.NewStructName}}
 	}
 	`
+	asMethod = `
+// As return a copy whose table is aliased and fields are bound to alias, eg: self-join
+func ({{.S}} {{.NewStructName}}) As(alias string) *{{.NewStructName}} {
+	{{.S}}.{{.NewStructName}}Do.UseAlias(alias)
+	return {{.S}}.updateTableName(alias)
+}
+
+func ({{.S}} *{{.NewStructName}}) updateTableName(table string) *{{.NewStructName}} {
+	{{.S}}.ALL = field.NewField(table, "*")
+	{{range .Members -}}
+	{{if not .IsRelation -}}
+		{{$.S}}.{{.Name}} = field.New{{.GenType}}(table, "{{.ColumnName}}")
+	{{end -}}
+	{{end}}
+	{{.S}}.fillFieldMap()
+	return {{.S}}
+}
+
+func ({{.S}} *{{.NewStructName}}) fillFieldMap() {
+	{{.S}}.fieldMap = make(map[string]field.Expr, {{len .Members}})
+	{{range .Members -}}
+	{{if not .IsRelation -}}
+		{{$.S}}.fieldMap["{{.ColumnName}}"] = {{$.S}}.{{.Name}}
+	{{end -}}
+	{{end -}}
+}
+`
 	members = `
 
 	ALL field.Field
@@ -73,7 +93,7 @@ func ({{.S}} {{.NewStructName}}) clone(db *gorm.DB) {{.NewStructName}} {
 }
 `
 	getFieldMethod = `
-func ({{.S}} *{{.NewStructName}}) GetFieldByName(fieldName string) (field.Expr, bool) {
+func ({{.S}} {{.NewStructName}}) GetFieldByName(fieldName string) (field.Expr, bool) {
 	field, ok := {{.S}}.fieldMap[fieldName]
 	return field, ok
 }
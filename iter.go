@@ -0,0 +1,77 @@
+package gen
+
+import (
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+// Iterator iterate query results row by row, only the current row is held in memory,
+// it must be closed after iteration, eg:
+//
+//	it, err := u.WithContext(ctx).Iter()
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		user := it.Value()
+//	}
+//	return it.Err()
+type Iterator struct {
+	db       *gorm.DB
+	rows     *sql.Rows
+	newValue func() interface{}
+
+	value interface{}
+	err   error
+}
+
+// Iter return iterator of query results, records are scanned when iterating
+func (d *DO) Iter() (it *Iterator, err error) {
+	// rows of iterator are not rendered in advance, which are consumed by the caller
+	if ok, err := d.intercept("Iter", func(d *DO) (err error) { it, err = d.Iter(); return err }, nil); ok {
+		return it, err
+	}
+
+	rows, err := d.Rows()
+	if err != nil {
+		return nil, err
+	}
+
+	newValue := d.newResultPointer
+	if d.model == nil {
+		newValue = func() interface{} { return map[string]interface{}{} }
+	}
+	return &Iterator{db: d.db.Model(d.model), rows: rows, newValue: newValue}, nil
+}
+
+// Next scan next row as value, return false if there is no more row or an error occurs
+func (it *Iterator) Next() bool {
+	it.value = nil
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+
+	value := it.newValue()
+	if err := it.db.ScanRows(it.rows, value); err != nil {
+		it.err = err
+		return false
+	}
+	it.value = value
+	return true
+}
+
+// Value return record of current row, it is a new pointer in each iteration and safe to keep
+func (it *Iterator) Value() interface{} { return it.value }
+
+// Err return error occurred in iteration
+func (it *Iterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close close underlying rows, rows are closed automatically after the last row is iterated
+func (it *Iterator) Close() error { return it.rows.Close() }
@@ -0,0 +1,118 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	lockingUpdate     = "UPDATE"
+	lockingShare      = "SHARE"
+	lockingSkipLocked = "SKIP LOCKED"
+	lockingNoWait     = "NOWAIT"
+)
+
+// lockingDialects dialects support row locking clause FOR UPDATE/FOR SHARE [OF ...] [SKIP LOCKED|NOWAIT],
+// FOR SHARE, OF, SKIP LOCKED and NOWAIT require MySQL 8.0+
+var lockingDialects = map[string]bool{
+	"mysql":    true,
+	"postgres": true,
+}
+
+// ForUpdate lock selected rows for update until the transaction ends, eg:
+//
+//	q.Transaction(func(tx *query.Query) error {
+//		job, err := tx.Job.Where(tx.Job.Status.Eq("pending")).ForUpdate().SkipLocked().First()
+//		...
+//	})
+//
+// locking reads always run on primary and are never cached
+func (d *DO) ForUpdate() Dao { return d.lock(lockingUpdate) }
+
+// ForShare lock selected rows in share mode until the transaction ends
+func (d *DO) ForShare() Dao { return d.lock(lockingShare) }
+
+// SkipLocked skip rows locked by other transactions, must follow ForUpdate or ForShare
+func (d *DO) SkipLocked() Dao { return d.lockOption("SkipLocked", lockingSkipLocked) }
+
+// NoWait fail immediately if rows are locked by other transactions, must follow ForUpdate or ForShare
+func (d *DO) NoWait() Dao { return d.lockOption("NoWait", lockingNoWait) }
+
+// Of lock rows of tables only, eg: o.Join(u, u.ID.EqCol(o.UserID)).ForUpdate().Of(o),
+// aliased table is referred by its alias, eg: Of(u.As("a")), must follow ForUpdate or ForShare
+func (d *DO) Of(tables ...schema.Tabler) Dao {
+	locking, ok := d.locking()
+	if !ok {
+		return d.withError(fmt.Errorf("Of must follow ForUpdate or ForShare"))
+	}
+	if len(tables) == 0 {
+		return d.withError(fmt.Errorf("Of: at least one table is required"))
+	}
+
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if table == nil || table.TableName() == "" {
+			return d.withError(fmt.Errorf("Of: table name is empty"))
+		}
+		name := table.TableName()
+		if aliased, ok := table.(interface{ Alias() string }); ok && aliased.Alias() != "" {
+			name = aliased.Alias()
+		}
+		names = append(names, d.db.Statement.Quote(name))
+	}
+	// tables are quoted already, clause.Locking writes raw name as is
+	locking.Table = clause.Table{Name: strings.Join(names, ","), Raw: true}
+	return d.getInstance(d.db.Clauses(locking))
+}
+
+func (d *DO) lock(strength string) Dao {
+	if err := d.checkLocking(); err != nil {
+		return d.withError(err)
+	}
+	// chained modifiers are kept, eg: ForUpdate().SkipLocked().ForShare()
+	locking, _ := d.locking()
+	locking.Strength = strength
+	d.warnLockingOutsideTx()
+	return d.getInstance(d.db.Clauses(locking))
+}
+
+func (d *DO) lockOption(method, option string) Dao {
+	locking, ok := d.locking()
+	if !ok {
+		return d.withError(fmt.Errorf("%s must follow ForUpdate or ForShare", method))
+	}
+	if locking.Options != "" && locking.Options != option {
+		return d.withError(fmt.Errorf("%s: conflicts with %s", method, locking.Options))
+	}
+	locking.Options = option
+	return d.getInstance(d.db.Clauses(locking))
+}
+
+// locking return locking clause of statement, ok is false if rows are not locked
+func (d *DO) locking() (locking clause.Locking, ok bool) {
+	c, ok := d.db.Statement.Clauses[locking.Name()]
+	if !ok {
+		return locking, false
+	}
+	locking, ok = c.Expression.(clause.Locking)
+	return locking, ok
+}
+
+func (d *DO) checkLocking() error {
+	if name := d.db.Dialector.Name(); !lockingDialects[name] {
+		return fmt.Errorf("row locking is not supported by %s", name)
+	}
+	return nil
+}
+
+// warnLockingOutsideTx warn that locks are released as soon as the statement ends outside transaction
+func (d *DO) warnLockingOutsideTx() {
+	if _, inTx := d.db.Statement.ConnPool.(gorm.TxCommitter); inTx || d.db.DryRun {
+		return
+	}
+	d.db.Logger.Warn(d.stmtContext(), "row locking of %s is used outside transaction, locks are released once the statement ends", d.TableName())
+}
@@ -0,0 +1,219 @@
+package gen
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// versionTagKey gorm tag key used to mark version column, eg: `gorm:"column:version;version"`
+const versionTagKey = "VERSION"
+
+// versionLock version column info of model for optimistic lock
+type versionLock struct {
+	field *schema.Field
+}
+
+// parseVersionLock find version column in model schema, return nil if not exists
+func parseVersionLock(s *schema.Schema) *versionLock {
+	if s == nil {
+		return nil
+	}
+	for _, f := range s.Fields {
+		if _, ok := f.TagSettings[versionTagKey]; ok && f.DBName != "" {
+			return &versionLock{field: f}
+		}
+	}
+	return nil
+}
+
+func (v *versionLock) column() string { return v.field.DBName }
+
+// eq condition matches rows with the expected version
+func (v *versionLock) eq(version interface{}) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: v.column()}, Value: version}
+}
+
+// increase assignment value increase version by one
+func (v *versionLock) increase() clause.Expr {
+	return clause.Expr{SQL: "? + 1", Vars: []interface{}{clause.Column{Name: v.column()}}}
+}
+
+// valueOf read version from model value
+func (v *versionLock) valueOf(rv reflect.Value) interface{} {
+	value, _ := v.field.ValueOf(rv)
+	return value
+}
+
+// bump increase version of model value by one after updated successfully
+func (v *versionLock) bump(rv reflect.Value) {
+	fv := reflect.Indirect(v.field.ReflectValueOf(rv))
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fv.SetInt(fv.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(fv.Uint() + 1)
+	}
+}
+
+// WithVersion specify the expected version for update finishers (including UpdateColumn family),
+// ErrOptimisticLock will be returned if no row matched with the version.
+// Without it, only model values with primary key are checked by their own version,
+// updates by conditions increase the version but don't compare it
+func (d *DO) WithVersion(version interface{}) Dao {
+	if d.version == nil {
+		return d.withError(ErrNoVersion)
+	}
+	d = d.getInstance(d.db)
+	d.expectedVersion, d.checkVersion = version, true
+	return d
+}
+
+// updateFunc update values on tx, eg: (*gorm.DB).Updates or (*gorm.DB).UpdateColumns
+type updateFunc func(tx *gorm.DB, values interface{}) *gorm.DB
+
+// updatesWithVersion update dest and increase version, check expected version if specified
+func (d *DO) updatesWithVersion(tx *gorm.DB, dest map[string]interface{}, expected interface{}, check bool) (info resultInfo, err error) {
+	return d.updateWithVersion(tx, dest, expected, check, (*gorm.DB).Updates)
+}
+
+// updateColumnsWithVersion like updatesWithVersion, without hooks and update time
+func (d *DO) updateColumnsWithVersion(tx *gorm.DB, dest map[string]interface{}, expected interface{}, check bool) (info resultInfo, err error) {
+	return d.updateWithVersion(tx, dest, expected, check, (*gorm.DB).UpdateColumns)
+}
+
+func (d *DO) updateWithVersion(tx *gorm.DB, dest map[string]interface{}, expected interface{}, check bool, update updateFunc) (info resultInfo, err error) {
+	values := make(map[string]interface{}, len(dest)+1)
+	for k, v := range dest {
+		values[k] = v
+	}
+	values[d.version.column()] = d.version.increase()
+
+	if check {
+		tx = tx.Where(d.version.eq(expected))
+	}
+	result := update(tx, values)
+	if check && result.Error == nil && result.RowsAffected == 0 {
+		return resultInfo{Error: ErrOptimisticLock}, ErrOptimisticLock
+	}
+	return resultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+}
+
+// updateModelWithVersion update non-zero fields of model value with optimistic lock,
+// model value with primary key is regarded as loaded from database and its version will be checked
+func (d *DO) updateModelWithVersion(value interface{}, update updateFunc) (info resultInfo, err error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Ptr {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		rv, value = ptr, ptr.Interface()
+	}
+	rv = rv.Elem()
+
+	expected, check := d.expectedVersion, d.checkVersion
+	if !check && !d.primaryKeyZero(rv) {
+		expected, check = d.version.valueOf(rv), true
+	}
+
+	info, err = d.updateWithVersion(d.db.Model(value), d.updatableValues(rv, false), expected, check, update)
+	if err == nil && check {
+		d.version.bump(rv)
+	}
+	return info, err
+}
+
+// saveWithVersion create records without primary key and update others with optimistic lock
+func (d *DO) saveWithVersion(value interface{}) error {
+	creates, updates, err := d.splitSaveRecords(value)
+	if err != nil {
+		return err
+	}
+
+	save := func(tx *gorm.DB) error {
+		if creates.Len() > 0 {
+			err := d.shardCreate(tx, creates.Interface(), func(tx *gorm.DB, value interface{}) error { return tx.Create(value).Error })
+			if err != nil {
+				return err
+			}
+		}
+		for _, record := range updates {
+			rv := record.Elem()
+			_, err := d.updatesWithVersion(tx.Model(record.Interface()), d.updatableValues(rv, true), d.version.valueOf(rv), true)
+			if err != nil {
+				return err
+			}
+			d.version.bump(rv)
+		}
+		return nil
+	}
+
+	// conditions of chain are dropped, scopes of model are kept
+	db := d.db.Session(&gorm.Session{NewDB: true})
+	if d.db.Statement.Unscoped {
+		db = db.Unscoped()
+	}
+	db = d.tenant.inherit(d.db, d.sharding.withScope(d.softDelete.withScope(db)))
+	if creates.Len()+len(updates) > 1 {
+		return transaction(db, save)
+	}
+	return save(db)
+}
+
+// updatableValues collect values of model to update, zero values are skipped unless withZero is true,
+// primary keys, version, auto create/update time and soft delete columns are excluded
+func (d *DO) updatableValues(rv reflect.Value, withZero bool) map[string]interface{} {
+	values := make(map[string]interface{}, len(d.schema.Fields))
+	for _, f := range d.schema.Fields {
+		if f.DBName == "" || !f.Updatable || f.PrimaryKey || f.AutoCreateTime > 0 || f.AutoUpdateTime > 0 || f == d.version.field {
+			continue
+		}
+		if d.softDelete != nil && f.DBName == d.softDelete.column {
+			continue
+		}
+		if v, isZero := f.ValueOf(rv); withZero || !isZero {
+			values[f.DBName] = v
+		}
+	}
+	return values
+}
+
+// splitSaveRecords split records to be saved into records without primary key to be created and others to be updated
+func (d *DO) splitSaveRecords(value interface{}) (creates reflect.Value, updates []reflect.Value, err error) {
+	rv := reflect.Indirect(reflect.ValueOf(value))
+
+	var records []reflect.Value
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			records = append(records, rv.Index(i))
+		}
+	} else {
+		records = append(records, reflect.ValueOf(value))
+	}
+
+	creates = reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(d.getModelType())), 0, len(records))
+	for _, record := range records {
+		if record.Kind() != reflect.Ptr {
+			return creates, nil, gorm.ErrInvalidValue
+		}
+		if d.primaryKeyZero(record.Elem()) {
+			creates = reflect.Append(creates, record)
+		} else {
+			updates = append(updates, record)
+		}
+	}
+	return creates, updates, nil
+}
+
+func (d *DO) primaryKeyZero(rv reflect.Value) bool {
+	if len(d.schema.PrimaryFields) == 0 {
+		return true
+	}
+	for _, f := range d.schema.PrimaryFields {
+		if _, isZero := f.ValueOf(rv); isZero {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/check"
+	"gorm.io/gen/internal/model"
+)
+
+// ScanInto scan results into dest (pointer to struct or slice of struct) like Scan, and check that selected columns
+// match fields of dest, eg:
+//
+//	var stats []model.UserStats
+//	err := u.WithContext(ctx).Select(u.Country, u.Age.Avg().As("avg_age")).Group(u.Country).ScanInto(&stats)
+//
+// ErrProjectionMismatch is returned if a selected column or alias is not mapped to any field of dest,
+// or a field of dest is not selected, which are zero values silently in Scan
+func (d *DO) ScanInto(dest interface{}) error {
+	// dest is not scanned in dry run, scan is rendered as is
+	scan := func(d *DO) error { return d.ScanInto(dest) }
+	if ok, err := d.intercept("ScanInto", scan, scan); ok {
+		return err
+	}
+
+	rows, err := d.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if err := checkProjection(d.db, dest, columns); err != nil {
+		return err
+	}
+
+	// rest rows are scanned into slice in ScanRows
+	if rows.Next() {
+		if err := d.db.Session(new(gorm.Session)).ScanRows(rows, dest); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// checkProjection check that columns are mapped to fields of dest one by one
+func checkProjection(db *gorm.DB, dest interface{}, columns []string) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return fmt.Errorf("ScanInto: dest must be pointer to struct or slice of struct, got %T: %w", dest, err)
+	}
+
+	selected := make(map[string]bool, len(columns))
+	var unmapped, missing []string
+	for _, column := range columns {
+		selected[column] = true
+		if f := stmt.Schema.LookUpField(column); f == nil || f.DBName == "" {
+			unmapped = append(unmapped, column)
+		}
+	}
+	for _, f := range stmt.Schema.Fields {
+		if f.DBName != "" && !selected[f.DBName] && !selected[f.Name] {
+			missing = append(missing, f.Name)
+		}
+	}
+
+	if len(unmapped) == 0 && len(missing) == 0 {
+		return nil
+	}
+	var details []string
+	if len(unmapped) > 0 {
+		details = append(details, fmt.Sprintf("columns %s are not mapped to fields", strings.Join(unmapped, ",")))
+	}
+	if len(missing) > 0 {
+		details = append(details, fmt.Sprintf("fields %s are not selected", strings.Join(missing, ",")))
+	}
+	return fmt.Errorf("%w: %s of %s", ErrProjectionMismatch, strings.Join(details, ", "), stmt.Schema.Name)
+}
+
+// GenerateProjection generate struct of selected columns in model package, which is the destination of Scan
+// or ScanInto, columns are named by alias of As or column name, eg:
+//
+//	g.GenerateProjection("UserStats", u.Country, u.Age.Avg().As("avg_age"))
+//
+// the above usage generates struct:
+//
+//	type UserStats struct {
+//		Country string  `gorm:"column:country" json:"country"`
+//		AvgAge  float64 `gorm:"column:avg_age" json:"avg_age"`
+//	}
+func (g *Generator) GenerateProjection(structName string, columns ...field.Expr) {
+	members := make([]*model.Member, 0, len(columns))
+	for _, column := range columns {
+		m, err := g.projectionMember(column)
+		if err == nil {
+			for _, exist := range members {
+				if exist.ColumnName == m.ColumnName {
+					err = fmt.Errorf("column %s is selected more than once", m.ColumnName)
+				}
+			}
+		}
+		if err != nil {
+			g.db.Logger.Error(context.Background(), "generate projection %s fail: %s", structName, err)
+			panic("generate projection fail")
+		}
+		members = append(members, m)
+	}
+
+	s, err := check.GenProjectionStruct(structName, g.ModelPkgPath, members)
+	if err != nil {
+		g.db.Logger.Error(context.Background(), "generate projection %s fail: %s", structName, err)
+		panic("generate projection fail")
+	}
+	g.projections = append(g.projections, s)
+}
+
+// projectionMember return member of selected column, typed by type of field
+func (g *Generator) projectionMember(column field.Expr) (*model.Member, error) {
+	var name string
+	switch e := column.RawExpr().(type) {
+	case clause.Column:
+		name = e.Name
+		if e.Alias != "" {
+			name = e.Alias
+		}
+	case clause.Expr:
+		// expression aliased by As: ? AS alias
+		if e.SQL == "? AS ?" && len(e.Vars) == 2 {
+			if alias, ok := e.Vars[1].(clause.Column); ok {
+				name = alias.Name
+			}
+		}
+	}
+	if name == "" || name == "*" {
+		return nil, fmt.Errorf("expression of projection must be a column or aliased by As")
+	}
+
+	typ := projectionType(column)
+	if typ == "" {
+		return nil, fmt.Errorf("type of column %s is unknown, use typed field", name)
+	}
+
+	fieldName := name
+	if ns, ok := g.db.NamingStrategy.(schema.NamingStrategy); ok {
+		ns.SingularTable = true
+		fieldName = ns.SchemaName(name)
+	} else {
+		fieldName = g.db.NamingStrategy.SchemaName(name)
+	}
+	return &model.Member{
+		Name:       fieldName,
+		Type:       typ,
+		ColumnName: name,
+		GORMTag:    "column:" + name,
+		JSONTag:    name,
+	}, nil
+}
+
+// projectionType return go type of field
+func projectionType(column field.Expr) string {
+	switch column.(type) {
+	case field.String:
+		return "string"
+	case field.Bytes:
+		return "[]byte"
+	case field.Bool:
+		return "bool"
+	case field.Int:
+		return "int"
+	case field.Int8:
+		return "int8"
+	case field.Int16:
+		return "int16"
+	case field.Int32:
+		return "int32"
+	case field.Int64:
+		return "int64"
+	case field.Uint:
+		return "uint"
+	case field.Uint8:
+		return "uint8"
+	case field.Uint16:
+		return "uint16"
+	case field.Uint32:
+		return "uint32"
+	case field.Uint64:
+		return "uint64"
+	case field.Float32:
+		return "float32"
+	case field.Float64:
+		return "float64"
+	case field.Time:
+		return "time.Time"
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package gen
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// replicasKey db setting key of replica connections
+	replicasKey = "gen:replicas"
+	// usePrimaryKey db setting key to force reads onto primary
+	usePrimaryKey = "gen:use_primary"
+)
+
+// replicas replica connections picked in round-robin
+type replicas struct {
+	pools []gorm.ConnPool
+	next  uint32
+}
+
+func (r *replicas) pick() gorm.ConnPool {
+	n := atomic.AddUint32(&r.next, 1) - 1
+	return r.pools[n%uint32(len(r.pools))]
+}
+
+// WithReplicas return primary db whose read finishers are routed to replicas,
+// No one should use it directly in project, use Use(db, replicas...) instead
+func WithReplicas(primary *gorm.DB, replicaDBs ...*gorm.DB) *gorm.DB {
+	if len(replicaDBs) == 0 {
+		return primary
+	}
+	r := &replicas{pools: make([]gorm.ConnPool, 0, len(replicaDBs))}
+	for _, replica := range replicaDBs {
+		r.pools = append(r.pools, replica.Statement.ConnPool)
+	}
+	return primary.Set(replicasKey, r).Session(new(gorm.Session))
+}
+
+// UsePrimary return db whose read finishers are routed to primary,
+// No one should use it directly in project, use Query.UsePrimary instead
+func UsePrimary(db *gorm.DB) *gorm.DB {
+	return db.Set(usePrimaryKey, true).Session(new(gorm.Session))
+}
+
+// UsePrimary force read finishers onto primary, eg: read your writes
+func (d *DO) UsePrimary() Dao {
+	return d.getInstance(UsePrimary(d.db))
+}
+
+// readDB return db for read finishers, which is routed to a replica
+// unless primary is forced, rows are locked or db is in transaction
+func (d *DO) readDB() *gorm.DB {
+	value, ok := d.db.Get(replicasKey)
+	if !ok {
+		return d.db
+	}
+	if usePrimary, _ := d.db.Get(usePrimaryKey); usePrimary == true {
+		return d.db
+	}
+	if _, locking := d.locking(); locking {
+		return d.db
+	}
+	if _, inTx := d.db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return d.db
+	}
+
+	ctx := d.db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// session with context clone the statement, so primary db is not affected
+	db := d.db.Session(&gorm.Session{Context: ctx})
+	db.Statement.ConnPool = value.(*replicas).pick()
+	return db
+}
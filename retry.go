@@ -0,0 +1,143 @@
+package gen
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// retryableCodes error codes of deadlocks and serialization failures by dialect,
+// which are resolved by running the whole transaction again, lock wait timeout of mysql(1205) is not
+// retried by default as every attempt may block for innodb_lock_wait_timeout, use RetryPolicy.Retryable for it
+var retryableCodes = map[string][]string{
+	"mysql":     {"1213"},           // deadlock found
+	"postgres":  {"40001", "40P01"}, // serialization failure, deadlock detected
+	"sqlserver": {"1205"},           // chosen as deadlock victim
+}
+
+// RetryPolicy policy of TransactionWithRetry, zero value retries 3 attempts in total with backoff from 10ms to 1s
+type RetryPolicy struct {
+	// MaxAttempts max attempts including the first one
+	MaxAttempts int
+	// BaseDelay delay before the second attempt, which is doubled by every attempt and jittered
+	BaseDelay time.Duration
+	// MaxDelay upper bound of delay
+	MaxDelay time.Duration
+	// Retryable classify retryable errors, IsRetryableTxError of dialect is used if it's nil
+	Retryable func(err error) bool
+}
+
+func (p RetryPolicy) withDefault() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 10 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = time.Second
+	}
+	return p
+}
+
+// delay return jittered delay before attempt, which is in [d/2, d] of exponential delay d
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.MaxDelay
+	if shift := uint(attempt - 2); shift < 32 && p.BaseDelay<<shift < p.MaxDelay {
+		d = p.BaseDelay << shift
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// IsRetryableTxError check if err is a deadlock or serialization failure of dialect(mysql, postgres or sqlserver),
+// the error code is read from Number(mysql, sqlserver), SQLState() or Code(postgres) of driver error
+func IsRetryableTxError(dialect string, err error) bool {
+	codes := retryableCodes[dialect]
+	for ; err != nil && len(codes) > 0; err = errors.Unwrap(err) {
+		code := errorCode(err)
+		for _, c := range codes {
+			if code == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// errorCode return code of driver error, eg: *mysql.MySQLError, *pgconn.PgError or *pq.Error
+func errorCode(err error) string {
+	if e, ok := err.(interface{ SQLState() string }); ok {
+		return e.SQLState()
+	}
+	value := reflect.ValueOf(err)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range []string{"Number", "Code"} {
+		switch f := value.FieldByName(name); f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return fmt.Sprint(f.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return fmt.Sprint(f.Uint())
+		case reflect.String:
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// TransactionWithRetry run fc in transaction, the transaction is run again with backoff if it fails with
+// retryable error (see IsRetryableTxError) until policy.MaxAttempts is reached, and the last error is returned.
+// fc may be called more than once, so side effects out of database (eg: sending message) should be done
+// after the transaction, or be idempotent. fc is called once if db is already in transaction, as the outer
+// transaction is aborted by the error and has to be retried as a whole. Backoff is stopped when context of db is done.
+// No one should use it directly in project, use Query.TransactionWithRetry instead
+func TransactionWithRetry(db *gorm.DB, fc func(tx *gorm.DB) error, policy RetryPolicy, opts ...*sql.TxOptions) error {
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return db.Transaction(fc, opts...)
+	}
+
+	policy = policy.withDefault()
+	retryable := policy.Retryable
+	if retryable == nil {
+		dialect := db.Dialector.Name()
+		retryable = func(err error) bool { return IsRetryableTxError(dialect, err) }
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := db.Transaction(fc, opts...)
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if attempt >= policy.MaxAttempts {
+			db.Logger.Warn(ctx, "transaction fail after %d attempts: %s", attempt, err)
+			return fmt.Errorf("transaction fail after %d attempts: %w", attempt, err)
+		}
+
+		delay := policy.delay(attempt + 1)
+		db.Logger.Warn(ctx, "transaction attempt %d/%d fail: %s, retry in %s", attempt, policy.MaxAttempts, err, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package gen
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"gorm.io/gen/field"
+)
+
+// scanColumn scan rows of single selected column into dest, next is called after each row is scanned
+// and return false to stop scanning
+func (d *DO) scanColumn(finisher string, dest interface{}, next func() bool) error {
+	if ok, err := d.intercept(finisher,
+		func(d *DO) error { return d.scanColumn(finisher, dest, next) },
+		func(d *DO) error { return d.scanColumn(finisher, dest, func() bool { return false }) },
+	); ok {
+		return err
+	}
+
+	rows, err := d.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) != 1 {
+		return fmt.Errorf("%s: expects 1 selected column, got %d", finisher, len(columns))
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest); err != nil {
+			return err
+		}
+		if !next() {
+			break
+		}
+	}
+	return rows.Err()
+}
+
+// selectColumn return DO selecting column only
+func (d *DO) selectColumn(column field.Expr) *DO {
+	return d.Select(column).(*DO)
+}
+
+// scanOne scan the first row of single selected column into dest, gorm.ErrRecordNotFound if there is no row
+func (d *DO) scanOne(finisher string, dest interface{}) error {
+	found := false
+	if err := d.scanColumn(finisher, dest, func() bool { found = true; return false }); err != nil {
+		return err
+	}
+	if !found {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PluckString pluck values of column, eg: u.PluckString(u.Email), NULL is plucked as zero value
+func (d *DO) PluckString(column field.String) (values []string, err error) {
+	var v sql.NullString
+	values = make([]string, 0)
+	err = d.selectColumn(column).scanColumn("PluckString", &v, func() bool { values = append(values, v.String); return true })
+	return values, err
+}
+
+// PluckInt pluck values of column, NULL is plucked as zero value
+func (d *DO) PluckInt(column field.Int) (values []int, err error) {
+	var v sql.NullInt64
+	values = make([]int, 0)
+	err = d.selectColumn(column).scanColumn("PluckInt", &v, func() bool { values = append(values, int(v.Int64)); return true })
+	return values, err
+}
+
+// PluckInt32 pluck values of column, NULL is plucked as zero value
+func (d *DO) PluckInt32(column field.Int32) (values []int32, err error) {
+	var v sql.NullInt32
+	values = make([]int32, 0)
+	err = d.selectColumn(column).scanColumn("PluckInt32", &v, func() bool { values = append(values, v.Int32); return true })
+	return values, err
+}
+
+// PluckInt64 pluck values of column, NULL is plucked as zero value
+func (d *DO) PluckInt64(column field.Int64) (values []int64, err error) {
+	var v sql.NullInt64
+	values = make([]int64, 0)
+	err = d.selectColumn(column).scanColumn("PluckInt64", &v, func() bool { values = append(values, v.Int64); return true })
+	return values, err
+}
+
+// PluckUint pluck values of column, NULL is plucked as zero value
+func (d *DO) PluckUint(column field.Uint) (values []uint, err error) {
+	var v nullUint64
+	values = make([]uint, 0)
+	err = d.selectColumn(column).scanColumn("PluckUint", &v, func() bool { values = append(values, uint(v.Uint64)); return true })
+	return values, err
+}
+
+// PluckFloat64 pluck values of column, NULL is plucked as zero value
+func (d *DO) PluckFloat64(column field.Float64) (values []float64, err error) {
+	var v sql.NullFloat64
+	values = make([]float64, 0)
+	err = d.selectColumn(column).scanColumn("PluckFloat64", &v, func() bool { values = append(values, v.Float64); return true })
+	return values, err
+}
+
+// PluckBool pluck values of column, NULL is plucked as zero value
+func (d *DO) PluckBool(column field.Bool) (values []bool, err error) {
+	var v sql.NullBool
+	values = make([]bool, 0)
+	err = d.selectColumn(column).scanColumn("PluckBool", &v, func() bool { values = append(values, v.Bool); return true })
+	return values, err
+}
+
+// PluckTime pluck values of column, NULL is plucked as zero value
+func (d *DO) PluckTime(column field.Time) (values []time.Time, err error) {
+	var v sql.NullTime
+	values = make([]time.Time, 0)
+	err = d.selectColumn(column).scanColumn("PluckTime", &v, func() bool { values = append(values, v.Time); return true })
+	return values, err
+}
+
+// ScanString scan single value of column, eg: u.Where(u.ID.Eq(1)).ScanString(u.Name),
+// NULL is scanned as zero value, gorm.ErrRecordNotFound is returned if there is no row
+func (d *DO) ScanString(column field.String) (value string, err error) {
+	var v sql.NullString
+	err = d.selectColumn(column).scanOne("ScanString", &v)
+	return v.String, err
+}
+
+// ScanInt scan single value of column, eg: u.ScanInt(u.ID.Count())
+func (d *DO) ScanInt(column field.Int) (value int, err error) {
+	var v sql.NullInt64
+	err = d.selectColumn(column).scanOne("ScanInt", &v)
+	return int(v.Int64), err
+}
+
+// ScanInt32 scan single value of column
+func (d *DO) ScanInt32(column field.Int32) (value int32, err error) {
+	var v sql.NullInt32
+	err = d.selectColumn(column).scanOne("ScanInt32", &v)
+	return v.Int32, err
+}
+
+// ScanInt64 scan single value of column
+func (d *DO) ScanInt64(column field.Int64) (value int64, err error) {
+	var v sql.NullInt64
+	err = d.selectColumn(column).scanOne("ScanInt64", &v)
+	return v.Int64, err
+}
+
+// ScanUint scan single value of column
+func (d *DO) ScanUint(column field.Uint) (value uint, err error) {
+	var v nullUint64
+	err = d.selectColumn(column).scanOne("ScanUint", &v)
+	return uint(v.Uint64), err
+}
+
+// ScanFloat64 scan single value of column, eg: u.ScanFloat64(u.Age.Max())
+func (d *DO) ScanFloat64(column field.Float64) (value float64, err error) {
+	var v sql.NullFloat64
+	err = d.selectColumn(column).scanOne("ScanFloat64", &v)
+	return v.Float64, err
+}
+
+// ScanBool scan single value of column
+func (d *DO) ScanBool(column field.Bool) (value bool, err error) {
+	var v sql.NullBool
+	err = d.selectColumn(column).scanOne("ScanBool", &v)
+	return v.Bool, err
+}
+
+// ScanTime scan single value of column, eg: u.Order(u.RegisterAt.Desc()).ScanTime(u.RegisterAt)
+func (d *DO) ScanTime(column field.Time) (value time.Time, err error) {
+	var v sql.NullTime
+	err = d.selectColumn(column).scanOne("ScanTime", &v)
+	return v.Time, err
+}
+
+// ScanOne scan single value of column into dest, eg: u.ScanOne(u.Age.Max(), &maxAge),
+// gorm.ErrRecordNotFound is returned if there is no row
+func (d *DO) ScanOne(column field.Expr, dest interface{}) error {
+	return d.selectColumn(column).scanOne("ScanOne", dest)
+}
+
+// nullUint64 scan unsigned integer without truncating values greater than math.MaxInt64
+type nullUint64 struct {
+	Uint64 uint64
+	Valid  bool
+}
+
+func (n *nullUint64) Scan(value interface{}) (err error) {
+	n.Uint64, n.Valid = 0, value != nil
+	switch v := value.(type) {
+	case nil:
+	case int64:
+		if v < 0 {
+			return fmt.Errorf("converting %d to uint64: value out of range", v)
+		}
+		n.Uint64 = uint64(v)
+	case uint64:
+		n.Uint64 = v
+	case []byte:
+		n.Uint64, err = strconv.ParseUint(string(v), 10, 64)
+	case string:
+		n.Uint64, err = strconv.ParseUint(v, 10, 64)
+	default:
+		return fmt.Errorf("unsupported type %T for uint64", value)
+	}
+	return err
+}
@@ -39,6 +39,9 @@ func checkClause(cond clause.Expression) error {
 	case clause.OnConflict:
 		return checkOnConflict(cond)
 	case clause.Interface:
+		if cond.Name() == "FOR" {
+			return fmt.Errorf("clause FOR is banned, use ForUpdate or ForShare instead")
+		}
 		if banClauses[cond.Name()] {
 			return fmt.Errorf("clause %s is banned", cond.Name())
 		}
@@ -51,7 +54,7 @@ func checkOnConflict(cond clause.OnConflict) error {
 	for _, item := range cond.DoUpdates {
 		switch item.Value.(type) {
 		case clause.Expr, *clause.Expr:
-			return fmt.Errorf("OnConflict clause assignment with gorm.Expr is banned for security reasons for now")
+			return fmt.Errorf("OnConflict clause assignment with gorm.Expr is banned for security reasons, use OnConflict().DoUpdate() instead")
 		}
 	}
 	return nil
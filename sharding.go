@@ -0,0 +1,535 @@
+package gen
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// shardSuffixKey db setting key of resolved table suffix
+	shardSuffixKey = "gen:shard_suffix"
+	// shardInsertKey db setting key marks insert statement, which can not use table alias
+	shardInsertKey = "gen:shard_insert"
+	// shardFanOutKey db setting key to query all shards when sharding key is missing
+	shardFanOutKey = "gen:shard_fan_out"
+)
+
+// Sharding sharding config of model, table name of shard is model's table name with suffix
+type Sharding struct {
+	Column   string                                           // sharding key column
+	Suffix   func(key interface{}) (suffix string, err error) // table suffix of sharding key value
+	Suffixes []string                                         // suffixes of all shards, required by FanOut
+}
+
+// Sharder model stored in sharded tables, eg:
+//
+//	func (Order) Sharding() gen.Sharding {
+//		return gen.Sharding{Column: "user_id", Suffix: func(key interface{}) (string, error) { ... }}
+//	}
+type Sharder interface {
+	Sharding() Sharding
+}
+
+// isSharder check if model or pointer of model implements Sharder
+func isSharder(model interface{}) bool {
+	if _, ok := model.(Sharder); ok {
+		return true
+	}
+	rt := reflect.TypeOf(model)
+	if rt == nil || rt.Kind() == reflect.Ptr {
+		return false
+	}
+	_, ok := reflect.New(rt).Interface().(Sharder)
+	return ok
+}
+
+// sharding sharding info of model
+type sharding struct {
+	Sharding
+
+	table string
+	field *schema.Field
+}
+
+// isKey check if column is sharding key column
+func (s *sharding) isKey(column interface{}) bool {
+	switch col := column.(type) {
+	case clause.Column:
+		return col.Name == s.Column && (col.Table == "" || col.Table == clause.CurrentTable || col.Table == s.table)
+	case string:
+		return col == s.Column
+	}
+	return false
+}
+
+// keysFromConds collect sharding key values from conditions joined by AND
+func (s *sharding) keysFromConds(exprs []clause.Expression) (keys []interface{}) {
+	for _, e := range exprs {
+		switch e := e.(type) {
+		case clause.Eq:
+			if s.isKey(e.Column) {
+				keys = append(keys, e.Value)
+			}
+		case clause.IN:
+			if s.isKey(e.Column) {
+				keys = append(keys, e.Values...)
+			}
+		case clause.AndConditions:
+			keys = append(keys, s.keysFromConds(e.Exprs)...)
+		}
+	}
+	return keys
+}
+
+// keysFromWhere collect sharding key values from where clause, conditions are untrusted if OR exists
+func (s *sharding) keysFromWhere(stmt *gorm.Statement) []interface{} {
+	c, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return nil
+	}
+	where, ok := c.Expression.(clause.Where)
+	if !ok {
+		return nil
+	}
+	for _, e := range where.Exprs {
+		if _, ok := e.(clause.OrConditions); ok {
+			return nil
+		}
+	}
+	return s.keysFromConds(where.Exprs)
+}
+
+// records return model records of value, value can be a record or slice of records
+func (s *sharding) records(value interface{}) (records []reflect.Value) {
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	isRecord := func(rv reflect.Value) bool {
+		return rv.IsValid() && rv.Kind() == reflect.Struct && rv.Type() == s.field.Schema.ModelType
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if elem := reflect.Indirect(rv.Index(i)); isRecord(elem) {
+				records = append(records, elem)
+			}
+		}
+	default:
+		if isRecord(rv) {
+			records = append(records, rv)
+		}
+	}
+	return records
+}
+
+// keyOf return sharding key value of record, ErrNoShardingKey if it is zero
+func (s *sharding) keyOf(rv reflect.Value) (interface{}, error) {
+	key, isZero := s.field.ValueOf(rv)
+	if isZero {
+		return nil, ErrNoShardingKey
+	}
+	return key, nil
+}
+
+// suffixOf return table suffix of keys, all keys must belong to the same shard
+func (s *sharding) suffixOf(keys []interface{}) (suffix string, err error) {
+	if len(keys) == 0 {
+		return "", ErrNoShardingKey
+	}
+	for i, key := range keys {
+		current, err := s.Suffix(key)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 && current != suffix {
+			return "", ErrCrossShard
+		}
+		suffix = current
+	}
+	return suffix, nil
+}
+
+// resolve table suffix of statement from explicit suffix, where clause or model values
+func (s *sharding) resolve(db *gorm.DB) (string, error) {
+	if suffix, ok := db.Get(shardSuffixKey); ok {
+		return suffix.(string), nil
+	}
+	if keys := s.keysFromWhere(db.Statement); len(keys) > 0 {
+		return s.suffixOf(keys)
+	}
+
+	value := db.Statement.Model
+	if value == nil {
+		value = db.Statement.Dest
+	}
+	var keys []interface{}
+	for _, record := range s.records(value) {
+		key, err := s.keyOf(record)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+	}
+	return s.suffixOf(keys)
+}
+
+// scope route statement to table of shard, raw sql is not affected
+func (s *sharding) scope(db *gorm.DB) *gorm.DB {
+	if db.Statement.SQL.Len() > 0 {
+		return db
+	}
+	suffix, err := s.resolve(db)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+
+	table := s.table + suffix
+	if insert, _ := db.Get(shardInsertKey); insert == true {
+		return db.Table(table)
+	}
+	// alias shard table as model's table, so that columns qualified with table name still work
+	db = db.Table("? AS ?", clause.Table{Name: table}, clause.Table{Name: s.table})
+	db.Statement.Table = s.table
+	return db
+}
+
+// withScope register sharding scope on db
+func (s *sharding) withScope(db *gorm.DB) *gorm.DB {
+	if s == nil {
+		return db
+	}
+	return db.Scopes(s.scope).Session(new(gorm.Session))
+}
+
+// inShard return db routed to shard with suffix
+func (s *sharding) inShard(db *gorm.DB, suffix string) *gorm.DB {
+	return db.Session(new(gorm.Session)).Set(shardSuffixKey, suffix).Session(new(gorm.Session))
+}
+
+// UseSharding specify sharding config, models implement Sharder are sharded in UseModel automatically
+func (d *DO) UseSharding(config Sharding) {
+	if d.schema == nil || config.Suffix == nil {
+		panic(ErrNoSharding)
+	}
+	f := d.schema.LookUpField(config.Column)
+	if f == nil {
+		panic(ErrNoShardingKey)
+	}
+	d.sharding = &sharding{Sharding: config, table: d.TableName(), field: f}
+	d.db = d.sharding.withScope(d.db)
+}
+
+// FanOut allow Find and Count without sharding key to query all shards and merge results,
+// results of Find are merge sorted by order columns, and limit and offset are applied to merged results
+func (d *DO) FanOut() Dao {
+	if d.sharding == nil {
+		return d.withError(ErrNoSharding)
+	}
+	return d.getInstance(d.db.Session(new(gorm.Session)).Set(shardFanOutKey, true).Session(new(gorm.Session)))
+}
+
+// fanOut check if query should be fanned out to all shards
+func (d *DO) fanOut() bool {
+	if d.sharding == nil {
+		return false
+	}
+	if fanOut, _ := d.db.Get(shardFanOutKey); fanOut != true {
+		return false
+	}
+	if _, ok := d.db.Get(shardSuffixKey); ok {
+		return false
+	}
+	_, err := d.sharding.suffixOf(d.sharding.keysFromWhere(d.db.Statement))
+	return err != nil
+}
+
+// fanOutFind find in all shards and merge results, each shard returns its first offset+limit rows,
+// which are merge sorted by order columns, then offset and limit are applied to merged results
+func (d *DO) fanOutFind() (interface{}, error) {
+	orders, err := d.fanOutOrders()
+	if err != nil {
+		return nil, err
+	}
+
+	db := d.db
+	limit, _ := db.Statement.Clauses[clause.Limit{}.Name()].Expression.(clause.Limit)
+	if limit.Offset > 0 {
+		db = db.Clauses()
+		c := db.Statement.Clauses[clause.Limit{}.Name()]
+		c.Expression = clause.Limit{Limit: limit.Limit + limit.Offset}
+		if limit.Limit <= 0 {
+			c.Expression = clause.Limit{}
+		}
+		db.Statement.Clauses[clause.Limit{}.Name()] = c
+	}
+
+	results := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(d.getModelType())), 0, 0)
+	for _, suffix := range d.sharding.Suffixes {
+		part, err := d.getInstance(d.sharding.inShard(db, suffix)).Find()
+		if err != nil {
+			return nil, err
+		}
+		results = reflect.AppendSlice(results, reflect.ValueOf(part))
+	}
+
+	if len(orders) > 0 {
+		sort.SliceStable(results.Interface(), func(i, j int) bool {
+			if err != nil {
+				return false
+			}
+			var less bool
+			less, err = lessByOrders(orders, results.Index(i).Elem(), results.Index(j).Elem())
+			return less
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if limit.Offset > 0 {
+		if limit.Offset > results.Len() {
+			limit.Offset = results.Len()
+		}
+		results = results.Slice(limit.Offset, results.Len())
+	}
+	if limit.Limit > 0 && limit.Limit < results.Len() {
+		results = results.Slice(0, limit.Limit)
+	}
+	return results.Interface(), nil
+}
+
+// fanOutOrder order column of fan out query
+type fanOutOrder struct {
+	field *schema.Field
+	desc  bool
+}
+
+// fanOutOrders parse ORDER BY clause into fields of model, orders by expressions can't be merged
+func (d *DO) fanOutOrders() ([]fanOutOrder, error) {
+	c, ok := d.db.Statement.Clauses[clause.OrderBy{}.Name()]
+	if !ok {
+		return nil, nil
+	}
+	orderBy, _ := c.Expression.(clause.OrderBy)
+	if c.AfterNameExpression != nil || orderBy.Expression != nil {
+		return nil, fmt.Errorf("FanOut: order by expression can't be merged, order by columns instead")
+	}
+
+	var orders []fanOutOrder
+	for _, column := range orderBy.Columns {
+		items := []string{column.Column.Name}
+		if column.Column.Raw {
+			items = strings.Split(column.Column.Name, ",")
+		}
+		for _, item := range items {
+			name, desc := strings.TrimSpace(item), column.Desc
+			if fields := strings.Fields(name); len(fields) == 2 && (strings.EqualFold(fields[1], "DESC") || strings.EqualFold(fields[1], "ASC")) {
+				name, desc = fields[0], desc || strings.EqualFold(fields[1], "DESC")
+			}
+			if i := strings.LastIndexByte(name, '.'); i >= 0 {
+				name = name[i+1:]
+			}
+			name = strings.Trim(name, "`\"[]")
+
+			f := d.schema.LookUpField(name)
+			if f == nil || f.DBName == "" {
+				return nil, fmt.Errorf("FanOut: order by %s can't be merged, order by columns of model instead", strings.TrimSpace(item))
+			}
+			orders = append(orders, fanOutOrder{field: f, desc: desc})
+		}
+	}
+	return orders, nil
+}
+
+// lessByOrders compare records by orders, NULL is less than any value like MySQL
+func lessByOrders(orders []fanOutOrder, a, b reflect.Value) (bool, error) {
+	for _, order := range orders {
+		x, _ := order.field.ValueOf(a)
+		y, _ := order.field.ValueOf(b)
+		result, err := compareValue(x, y)
+		if err != nil {
+			return false, fmt.Errorf("FanOut: order by %s: %w", order.field.DBName, err)
+		}
+		if result != 0 {
+			return result < 0 != order.desc, nil
+		}
+	}
+	return false, nil
+}
+
+// compareValue compare values of order column, return -1, 0 or 1
+func compareValue(x, y interface{}) (int, error) {
+	var err error
+	if x, err = orderValue(x); err != nil {
+		return 0, err
+	}
+	if y, err = orderValue(y); err != nil {
+		return 0, err
+	}
+	switch {
+	case x == nil && y == nil:
+		return 0, nil
+	case x == nil:
+		return -1, nil
+	case y == nil:
+		return 1, nil
+	}
+
+	if tx, ok := x.(time.Time); ok {
+		ty, _ := y.(time.Time)
+		switch {
+		case tx.Before(ty):
+			return -1, nil
+		case tx.After(ty):
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	if vx.Kind() != vy.Kind() {
+		return 0, fmt.Errorf("can't compare %T with %T", x, y)
+	}
+	switch vx.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(vx.Int() < vy.Int(), vx.Int() > vy.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(vx.Uint() < vy.Uint(), vx.Uint() > vy.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(vx.Float() < vy.Float(), vx.Float() > vy.Float()), nil
+	case reflect.String:
+		return strings.Compare(vx.String(), vy.String()), nil
+	case reflect.Bool:
+		return compareOrdered(!vx.Bool() && vy.Bool(), vx.Bool() && !vy.Bool()), nil
+	}
+	return 0, fmt.Errorf("can't compare values of %T", x)
+}
+
+// orderValue dereference pointer and driver.Valuer, nil is returned for NULL
+func orderValue(v interface{}) (interface{}, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil, nil
+		}
+		return valuer.Value()
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	return rv.Interface(), nil
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// fanOutCount count in all shards and sum up
+func (d *DO) fanOutCount() (count int64, err error) {
+	for _, suffix := range d.sharding.Suffixes {
+		n, err := d.getInstance(d.sharding.inShard(d.db, suffix)).Count()
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// shardCreate create value in shards of its records, records of different shards are created in a transaction
+func (d *DO) shardCreate(db *gorm.DB, value interface{}, create func(tx *gorm.DB, value interface{}) error) error {
+	s := d.sharding
+	if s == nil {
+		return create(db, value)
+	}
+
+	var (
+		suffixes []string
+		groups   = make(map[string]reflect.Value)
+		rv       = reflect.Indirect(reflect.ValueOf(value))
+		isSlice  = rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+		length   = 1
+	)
+	if isSlice {
+		length = rv.Len()
+	}
+	for i := 0; i < length; i++ {
+		item := rv
+		if isSlice {
+			item = rv.Index(i)
+		}
+		records := s.records(item.Interface())
+		if len(records) == 0 {
+			return gorm.ErrInvalidValue
+		}
+		key, err := s.keyOf(records[0])
+		if err != nil {
+			return err
+		}
+		suffix, err := s.Suffix(key)
+		if err != nil {
+			return err
+		}
+		if _, ok := groups[suffix]; !ok {
+			suffixes = append(suffixes, suffix)
+			if isSlice {
+				groups[suffix] = reflect.MakeSlice(reflect.SliceOf(rv.Type().Elem()), 0, rv.Len())
+			}
+		}
+		if isSlice {
+			groups[suffix] = reflect.Append(groups[suffix], item)
+		}
+	}
+
+	insert := func(tx *gorm.DB) error {
+		for _, suffix := range suffixes {
+			shardValue := value
+			if isSlice {
+				shardValue = groups[suffix].Interface()
+			}
+			if err := create(s.inShard(tx, suffix).Set(shardInsertKey, true), shardValue); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(suffixes) > 1 {
+		return transaction(db, insert)
+	}
+	return insert(db)
+}
+
+// shardFirstOrCreate find first record in shard or create it, the record is initialized like FirstOrInit
+// and created in shard of its sharding key, found record is not updated with Assign
+func (d *DO) shardFirstOrCreate() (interface{}, error) {
+	result := d.newResultPointer()
+	tx := d.db.FirstOrInit(result)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		if err := d.Create(result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
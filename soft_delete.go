@@ -0,0 +1,150 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// SoftDeleteMode how a soft delete column marks a row as deleted
+type SoftDeleteMode string
+
+const (
+	// SoftDeleteDeletedAt gorm.DeletedAt column, NULL for alive rows and deleted time for deleted rows
+	SoftDeleteDeletedAt SoftDeleteMode = "deleted_at"
+
+	// SoftDeleteFlag integer/bool flag column, 0 for alive rows and 1 for deleted rows
+	SoftDeleteFlag SoftDeleteMode = "flag"
+
+	// SoftDeleteUnix integer column, 0 for alive rows and unix seconds of deleted time for deleted rows
+	SoftDeleteUnix SoftDeleteMode = "unix"
+
+	// SoftDeleteMilli integer column, 0 for alive rows and unix milliseconds of deleted time for deleted rows
+	SoftDeleteMilli SoftDeleteMode = "milli"
+
+	// SoftDeleteNano integer column, 0 for alive rows and unix nanoseconds of deleted time for deleted rows
+	SoftDeleteNano SoftDeleteMode = "nano"
+)
+
+// softDeleteTagKey gorm tag key used to mark custom soft delete column, eg: `gorm:"column:is_deleted;softDelete:flag"`,
+// values are the same as gorm.io/plugin/soft_delete, tag without value is unix seconds
+const softDeleteTagKey = "SOFTDELETE"
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// softDelete soft delete column info of model
+type softDelete struct {
+	column string
+	mode   SoftDeleteMode
+	byGorm bool // scope and delete are handled by gorm, eg: gorm.DeletedAt or soft_delete.DeletedAt of gorm.io/plugin/soft_delete
+}
+
+// parseSoftDelete find soft delete column in model schema, return nil if not exists
+func parseSoftDelete(s *schema.Schema) (*softDelete, error) {
+	if s == nil {
+		return nil, nil
+	}
+	for _, f := range s.Fields {
+		if f.DBName == "" {
+			continue
+		}
+		if f.IndirectFieldType == deletedAtType {
+			return &softDelete{column: f.DBName, mode: SoftDeleteDeletedAt, byGorm: true}, nil
+		}
+
+		// types with delete clauses (eg: soft_delete.DeletedAt) apply soft delete by themselves
+		_, byGorm := reflect.New(f.IndirectFieldType).Interface().(schema.DeleteClausesInterface)
+		setting, ok := f.TagSettings[softDeleteTagKey]
+		if !ok && !byGorm {
+			continue
+		}
+		mode, err := parseSoftDeleteMode(setting)
+		if err != nil {
+			return nil, fmt.Errorf("soft delete field %s: %w", f.Name, err)
+		}
+		return &softDelete{column: f.DBName, mode: mode, byGorm: byGorm}, nil
+	}
+	return nil, nil
+}
+
+// parseSoftDeleteMode parse value of `softDelete` tag
+func parseSoftDeleteMode(setting string) (SoftDeleteMode, error) {
+	switch mode := SoftDeleteMode(strings.ToLower(setting)); mode {
+	case "", "softdelete": // tag without value
+		return SoftDeleteUnix, nil
+	case SoftDeleteFlag, SoftDeleteUnix, SoftDeleteMilli, SoftDeleteNano:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown softDelete tag %q, should be flag, unix, milli or nano", setting)
+}
+
+// native whether soft delete is handled by gorm itself
+func (s *softDelete) native() bool { return s.byGorm }
+
+// nullable whether alive rows are NULL, which are 0 otherwise
+func (s *softDelete) nullable() bool { return s.mode == SoftDeleteDeletedAt }
+
+func (s *softDelete) col() clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: s.column}
+}
+
+// aliveCond condition matches rows not deleted
+func (s *softDelete) aliveCond() clause.Expression {
+	if s.nullable() {
+		return clause.Eq{Column: s.col(), Value: nil}
+	}
+	return clause.Eq{Column: s.col(), Value: 0}
+}
+
+// deletedCond condition matches rows deleted
+func (s *softDelete) deletedCond() clause.Expression {
+	if s.nullable() {
+		return clause.Neq{Column: s.col(), Value: nil}
+	}
+	return clause.Neq{Column: s.col(), Value: 0}
+}
+
+// aliveValue value set to column when restore rows
+func (s *softDelete) aliveValue() interface{} {
+	if s.nullable() {
+		return nil
+	}
+	return 0
+}
+
+// deletedValue value set to column when soft delete rows
+func (s *softDelete) deletedValue() interface{} {
+	switch s.mode {
+	case SoftDeleteFlag:
+		return 1
+	case SoftDeleteUnix:
+		return time.Now().Unix()
+	case SoftDeleteMilli:
+		return time.Now().UnixNano() / int64(time.Millisecond)
+	case SoftDeleteNano:
+		return time.Now().UnixNano()
+	default:
+		return time.Now()
+	}
+}
+
+// scope filter deleted rows out for custom soft delete column, gorm.DeletedAt is handled by gorm
+func (s *softDelete) scope(db *gorm.DB) *gorm.DB {
+	if db.Statement.Unscoped {
+		return db
+	}
+	return db.Where(s.aliveCond())
+}
+
+// withScope register soft delete scope on db
+func (s *softDelete) withScope(db *gorm.DB) *gorm.DB {
+	if s == nil || s.native() {
+		return db
+	}
+	return db.Scopes(s.scope).Session(new(gorm.Session))
+}
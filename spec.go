@@ -0,0 +1,215 @@
+package gen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/utils"
+)
+
+// FieldGetter get field by column name, generated query structs implement it by GetFieldByName
+type FieldGetter interface {
+	GetFieldByName(fieldName string) (field.Expr, bool)
+}
+
+// AllowAll allow all columns of query struct in ParseFilter and ParseSort, which must be explicit,
+// eg: gen.ParseFilter(u, spec, gen.AllowAll)
+const AllowAll = "*"
+
+// filterOperators operators of filter spec, longer operators are matched first
+var filterOperators = []string{">=", "<=", "!=", ">", "<", "=", "~"}
+
+// ParseFilter parse filter spec (eg: request parameter) into conditions, fields are resolved by column name
+// through fields, and only allowed columns are accepted, no column is accepted if allowed is empty, eg:
+//
+//	conds, err := gen.ParseFilter(u, "age>18,name~bob,id=1|2|3", "age", "name", "id")
+//	users, err := u.WithContext(ctx).Where(conds...).Find()
+//
+// the above usage is equivalent to SQL statement:
+//
+//	SELECT * FROM `users` WHERE `users`.`age` > 18 AND `users`.`name` LIKE "%bob%" AND `users`.`id` IN (1,2,3)
+//
+// terms are separated by comma, operators are =, !=, >, >=, <, <= and ~(LIKE %value%, value is matched literally),
+// values separated by | are matched by IN (=) or NOT IN (!=), values are parsed to type of field
+func ParseFilter(fields FieldGetter, spec string, allowed ...string) ([]Condition, error) {
+	var conds []Condition
+	for _, term := range strings.Split(spec, ",") {
+		if term = strings.TrimSpace(term); term == "" {
+			continue
+		}
+
+		name, op, value, err := splitFilterTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		f, err := lookupField(fields, name, allowed)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := filterTermExpr(f, op, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidSpec, term, err)
+		}
+		conds = append(conds, &condContainer{value: expr})
+	}
+	return conds, nil
+}
+
+// ParseSort parse sort spec (eg: request parameter) into orders, columns are separated by comma and
+// descending with prefix -, only allowed columns are accepted like ParseFilter, eg: gen.ParseSort(u, "-created_at,name", "created_at", "name")
+func ParseSort(fields FieldGetter, spec string, allowed ...string) ([]field.Expr, error) {
+	var orders []field.Expr
+	for _, term := range strings.Split(spec, ",") {
+		if term = strings.TrimSpace(term); term == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(term, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(term, "-"), "+")
+		f, err := lookupField(fields, name, allowed)
+		if err != nil {
+			return nil, err
+		}
+		if desc {
+			d, ok := f.(interface{ Desc() field.Expr })
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: field can not be sorted descending", ErrInvalidSpec, term)
+			}
+			f = d.Desc()
+		}
+		orders = append(orders, f)
+	}
+	return orders, nil
+}
+
+// splitFilterTerm split term into column name, operator and value, eg: age>=18
+func splitFilterTerm(term string) (name, op, value string, err error) {
+	i := strings.IndexAny(term, "=!<>~")
+	if i <= 0 {
+		return "", "", "", fmt.Errorf("%w: %s: operator or column is missing", ErrInvalidSpec, term)
+	}
+	name, rest := strings.TrimSpace(term[:i]), term[i:]
+	for _, o := range filterOperators {
+		if strings.HasPrefix(rest, o) {
+			return name, o, strings.TrimSpace(rest[len(o):]), nil
+		}
+	}
+	return "", "", "", fmt.Errorf("%w: %s: unsupported operator", ErrInvalidSpec, term)
+}
+
+// lookupField resolve field by column name, the column must be allowed, or all columns are allowed by AllowAll
+func lookupField(fields FieldGetter, name string, allowed []string) (field.Expr, error) {
+	if !utils.ListContain(AllowAll, allowed) && !utils.ListContain(name, allowed) {
+		return nil, fmt.Errorf("%w: %s", ErrFieldNotAllowed, name)
+	}
+	f, ok := fields.GetFieldByName(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFieldNotAllowed, name)
+	}
+	return f, nil
+}
+
+func filterTermExpr(f field.Expr, op, value string) (clause.Expression, error) {
+	column, ok := f.RawExpr().(clause.Column)
+	if !ok {
+		return nil, fmt.Errorf("field is not a column")
+	}
+
+	if op == "~" {
+		if _, ok := f.(field.String); !ok {
+			return nil, fmt.Errorf("operator ~ requires string field")
+		}
+		return escapedLike{column: column, pattern: "%" + likeEscaper.Replace(value) + "%"}, nil
+	}
+
+	if (op == "=" || op == "!=") && strings.Contains(value, "|") {
+		items := strings.Split(value, "|")
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := parseFieldValue(f, item)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		if op == "!=" {
+			return clause.Not(clause.IN{Column: column, Values: values}), nil
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	}
+
+	v, err := parseFieldValue(f, value)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "=":
+		return clause.Eq{Column: column, Value: v}, nil
+	case "!=":
+		return clause.Neq{Column: column, Value: v}, nil
+	case ">":
+		return clause.Gt{Column: column, Value: v}, nil
+	case ">=":
+		return clause.Gte{Column: column, Value: v}, nil
+	case "<":
+		return clause.Lt{Column: column, Value: v}, nil
+	default: // <=
+		return clause.Lte{Column: column, Value: v}, nil
+	}
+}
+
+// parseFieldValue parse value to type of field, eg: int of field.Int
+func parseFieldValue(f field.Expr, value string) (interface{}, error) {
+	switch f.(type) {
+	case field.String:
+		return value, nil
+	case field.Bytes:
+		return []byte(value), nil
+	case field.Bool:
+		return strconv.ParseBool(value)
+	case field.Int:
+		v, err := strconv.ParseInt(value, 10, 0)
+		return int(v), err
+	case field.Int8:
+		v, err := strconv.ParseInt(value, 10, 8)
+		return int8(v), err
+	case field.Int16:
+		v, err := strconv.ParseInt(value, 10, 16)
+		return int16(v), err
+	case field.Int32:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return int32(v), err
+	case field.Int64:
+		return strconv.ParseInt(value, 10, 64)
+	case field.Uint:
+		v, err := strconv.ParseUint(value, 10, 0)
+		return uint(v), err
+	case field.Uint8:
+		v, err := strconv.ParseUint(value, 10, 8)
+		return uint8(v), err
+	case field.Uint16:
+		v, err := strconv.ParseUint(value, 10, 16)
+		return uint16(v), err
+	case field.Uint32:
+		v, err := strconv.ParseUint(value, 10, 32)
+		return uint32(v), err
+	case field.Uint64:
+		return strconv.ParseUint(value, 10, 64)
+	case field.Float32:
+		v, err := strconv.ParseFloat(value, 32)
+		return float32(v), err
+	case field.Float64:
+		return strconv.ParseFloat(value, 64)
+	case field.Time:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", value)
+	}
+	return nil, fmt.Errorf("type %T of field is not supported", f)
+}
@@ -0,0 +1,228 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// tenantIDKey db setting key of current tenant id
+	tenantIDKey = "gen:tenant_id"
+	// tenantUnscopedKey db setting key to skip tenant scope
+	tenantUnscopedKey = "gen:tenant_unscoped"
+)
+
+type tenantCtxKey struct{}
+
+// ContextWithTenant return a copy of ctx carrying tenant id, which can be passed to Query.WithTenant
+func ContextWithTenant(ctx context.Context, tenantID interface{}) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenantID)
+}
+
+// TenantFromContext return tenant id carried by ctx
+func TenantFromContext(ctx context.Context) (tenantID interface{}, ok bool) {
+	tenantID = ctx.Value(tenantCtxKey{})
+	return tenantID, tenantID != nil
+}
+
+// WithTenant return db scoped with tenant, tenant is tenant id or context.Context carrying tenant id,
+// No one should use it directly in project, use Query.WithTenant instead
+func WithTenant(db *gorm.DB, tenant interface{}) *gorm.DB {
+	if ctx, ok := tenant.(context.Context); ok {
+		tenant, _ = TenantFromContext(ctx)
+	}
+	if tenant == nil {
+		return db
+	}
+	return db.Set(tenantIDKey, tenant).Set(tenantUnscopedKey, false).Session(new(gorm.Session))
+}
+
+// UnscopedTenant return db without tenant scope,
+// No one should use it directly in project, use Query.UnscopedTenant instead
+func UnscopedTenant(db *gorm.DB) *gorm.DB {
+	return db.Set(tenantUnscopedKey, true).Session(new(gorm.Session))
+}
+
+// tenantScope tenant column info of model
+type tenantScope struct {
+	column string
+}
+
+// unscoped whether tenant scope is skipped explicitly
+func (*tenantScope) unscoped(db *gorm.DB) bool {
+	unscoped, _ := db.Get(tenantUnscopedKey)
+	return unscoped == true
+}
+
+// tenantID return current tenant id of db
+func (*tenantScope) tenantID(db *gorm.DB) (interface{}, bool) {
+	return db.Get(tenantIDKey)
+}
+
+// scope add tenant condition to reads, updates and deletes, raw sql is not affected
+func (t *tenantScope) scope(db *gorm.DB) *gorm.DB {
+	if t.unscoped(db) || db.Statement.SQL.Len() > 0 {
+		return db
+	}
+	tenantID, ok := t.tenantID(db)
+	if !ok {
+		_ = db.AddError(ErrNoTenant)
+		return db
+	}
+	return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: t.column}, Value: tenantID})
+}
+
+// withScope register tenant scope on db
+func (t *tenantScope) withScope(db *gorm.DB) *gorm.DB {
+	if t == nil {
+		return db
+	}
+	return db.Scopes(t.scope).Session(new(gorm.Session))
+}
+
+// inherit carry tenant settings of from to db which has a new statement, and register tenant scope
+func (t *tenantScope) inherit(from, db *gorm.DB) *gorm.DB {
+	if t == nil {
+		return db
+	}
+	if tenantID, ok := t.tenantID(from); ok {
+		db = db.Set(tenantIDKey, tenantID)
+	}
+	return t.withScope(db.Set(tenantUnscopedKey, t.unscoped(from)))
+}
+
+// UseTenant specify tenant column, all finishers will be scoped with tenant specified by WithTenant
+func (d *DO) UseTenant(column string) {
+	if d.schema == nil || d.schema.LookUpField(column) == nil {
+		panic(ErrNoTenantField)
+	}
+	d.tenant = &tenantScope{column: column}
+	d.db = d.tenant.withScope(d.db)
+}
+
+// WithTenant return a DO scoped with tenant, tenant is tenant id or context.Context carrying tenant id
+func (d *DO) WithTenant(tenant interface{}) Dao {
+	if d.tenant == nil {
+		return d.withError(ErrNoTenantField)
+	}
+	return d.getInstance(WithTenant(d.db, tenant))
+}
+
+// UnscopedTenant return a DO without tenant scope, crossing tenants must be explicit
+func (d *DO) UnscopedTenant() Dao {
+	return d.getInstance(UnscopedTenant(d.db))
+}
+
+// saveWithTenant create records without primary key and update others within current tenant, records not found
+// in current tenant are created, rows of other tenants are never overwritten by upsert
+func (d *DO) saveWithTenant(value interface{}) error {
+	creates, updates, err := d.splitSaveRecords(value)
+	if err != nil {
+		return err
+	}
+
+	save := func(tx *gorm.DB) error {
+		if creates.Len() > 0 {
+			err := d.shardCreate(tx, creates.Interface(), func(tx *gorm.DB, value interface{}) error { return tx.Create(value).Error })
+			if err != nil {
+				return err
+			}
+		}
+		for _, record := range updates {
+			// UPDATE ... WHERE pk = ? AND tenant_id = ?
+			result := tx.Model(record.Interface()).Select("*").Updates(record.Interface())
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 && !tx.DryRun {
+				// duplicated key error is returned by database if the primary key belongs to other tenant
+				err := d.shardCreate(tx, record.Interface(), func(tx *gorm.DB, value interface{}) error { return tx.Create(value).Error })
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if creates.Len()+len(updates) > 1 {
+		return transaction(d.db, save)
+	}
+	return save(d.db)
+}
+
+// assignTenant set tenant id to values to be created, values of other tenants are rejected
+func (d *DO) assignTenant(value interface{}) error {
+	t := d.tenant
+	if t == nil || t.unscoped(d.db) {
+		return nil
+	}
+	tenantID, ok := t.tenantID(d.db)
+	if !ok {
+		return ErrNoTenant
+	}
+
+	f := d.schema.LookUpField(t.column)
+	tenantID, err := convertTenantID(tenantID, f.FieldType)
+	if err != nil {
+		return err
+	}
+
+	assign := func(rv reflect.Value) error {
+		rv = reflect.Indirect(rv)
+		if rv.Kind() != reflect.Struct {
+			return nil
+		}
+		current, isZero := f.ValueOf(rv)
+		if isZero {
+			return f.Set(rv, tenantID)
+		}
+		if !reflect.DeepEqual(current, tenantID) {
+			return ErrCrossTenant
+		}
+		return nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return assign(rv)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := assign(rv.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertTenantID convert tenant id to type of tenant field, only identical types and numbers are converted,
+// eg: int tenant id is not converted to string field, which is a one-rune string
+func convertTenantID(tenantID interface{}, typ reflect.Type) (interface{}, error) {
+	value := reflect.ValueOf(tenantID)
+	if value.Type() == typ {
+		return tenantID, nil
+	}
+	if !isNumberKind(value.Kind()) || !isNumberKind(typ.Kind()) {
+		return nil, fmt.Errorf("%w: %T to %s", ErrTenantType, tenantID, typ)
+	}
+
+	converted := value.Convert(typ)
+	if converted.Convert(value.Type()).Interface() != tenantID {
+		return nil, fmt.Errorf("%w: %v overflows %s", ErrTenantType, tenantID, typ)
+	}
+	return converted.Interface(), nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package gen
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SQLStatement statement rendered without executing
+type SQLStatement struct {
+	SQL          string        // statement with placeholders
+	Vars         []interface{} // bind variables
+	Interpolated string        // statement with vars interpolated, for debugging only
+}
+
+// String return interpolated statement
+func (s SQLStatement) String() string { return s.Interpolated }
+
+// sqlRecorder records statements rendered in dry run mode and errors of them
+type sqlRecorder struct {
+	stmts []SQLStatement
+	errs  []error
+}
+
+// recordDialector dialector records statements explained by logger
+type recordDialector struct {
+	gorm.Dialector
+	r *sqlRecorder
+}
+
+func (d recordDialector) Explain(sql string, vars ...interface{}) string {
+	interpolated := d.Dialector.Explain(sql, vars...)
+	if sql != "" {
+		d.r.stmts = append(d.r.stmts, SQLStatement{SQL: sql, Vars: vars, Interpolated: interpolated})
+		d.r.errs = append(d.r.errs, nil)
+	}
+	return interpolated
+}
+
+// recordLogger logger explains every statement, so that it is recorded whatever the log level is
+type recordLogger struct {
+	logger.Interface
+	r *sqlRecorder
+}
+
+func (l recordLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return recordLogger{Interface: l.Interface.LogMode(level), r: l.r}
+}
+
+func (l recordLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	sql, rows := fc()
+	if sql != "" && len(l.r.errs) > 0 {
+		l.r.errs[len(l.r.errs)-1] = err
+	}
+	l.Interface.Trace(ctx, begin, func() (string, int64) { return sql, rows }, err)
+}
+
+// ToSQL render statement of finisher in dry run mode without touching database, eg:
+//
+//	stmt, err := u.Where(u.Age.Gt(18)).Order(u.ID).Limit(10).ToSQL(func(tx gen.Dao) error {
+//		_, err := tx.Find()
+//		return err
+//	})
+//
+// the last statement is returned if finisher runs several statements, eg: FindByPage
+func (d *DO) ToSQL(finisher func(tx Dao) error) (SQLStatement, error) {
+	r := new(sqlRecorder)
+	db := d.db.Session(&gorm.Session{DryRun: true, SkipDefaultTransaction: true, Logger: recordLogger{Interface: d.db.Logger, r: r}})
+	db.Config.Dialector = recordDialector{Dialector: db.Dialector, r: r}
+
+	// interceptors and cache are skipped, statements are rendered only
+	err := finisher(d.getInstance(db.Set(interceptingKey, true).Session(new(gorm.Session))))
+	if len(r.stmts) == 0 {
+		if err == nil {
+			err = ErrNoStatement
+		}
+		return SQLStatement{}, err
+	}
+	// errors of dry run results are ignored, eg: ErrOptimisticLock as no row is affected
+	last := len(r.stmts) - 1
+	return r.stmts[last], r.errs[last]
+}
+
+// transaction run fc in transaction of db, fc is run on db directly in dry run mode, eg: ToSQL,
+// as DryRun doesn't stop db.Transaction from beginning a transaction on the connection
+func transaction(db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	if db.DryRun {
+		return fc(db)
+	}
+	return db.Transaction(fc)
+}
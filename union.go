@@ -0,0 +1,179 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Union combine results of queries and remove duplicate rows, the result can be ordered, limited,
+// and used as subquery or source of Table
+//
+//	Union(u.Select(u.ID, u.Name).Where(u.Age.Lt(18)), u.Select(u.ID, u.Name).Where(u.Age.Gt(60))).Order(u.ID).Limit(10)
+//
+// the above usage is equivalent to SQL statement:
+//
+//	SELECT * FROM (SELECT `id`,`name` FROM `users_info` WHERE `age` < ? UNION SELECT `id`,`name` FROM `users_info` WHERE `age` > ?) AS `users_info` ORDER BY `id` LIMIT 10
+//
+// the combined result is aliased as table of the first query, whose model is kept, conditions of the model
+// (eg: soft delete) are not added to the combined result as queries are already filtered.
+// ORDER BY and LIMIT of a single query are not supported by SQLite, which doesn't allow parenthesized queries in UNION
+func Union(queries ...subQuery) Dao { return union("UNION", queries) }
+
+// UnionAll combine results of queries and keep duplicate rows
+func UnionAll(queries ...subQuery) Dao { return union("UNION ALL", queries) }
+
+// Union combine results with other queries and remove duplicate rows, eg: u.Where(u.Age.Lt(18)).Union(u.Where(u.Age.Gt(60)))
+func (d *DO) Union(others ...Dao) Dao { return union("UNION", d.withQueries(others)) }
+
+// UnionAll combine results with other queries and keep duplicate rows
+func (d *DO) UnionAll(others ...Dao) Dao { return union("UNION ALL", d.withQueries(others)) }
+
+func (d *DO) withQueries(others []Dao) []subQuery {
+	queries := make([]subQuery, 0, len(others)+1)
+	queries = append(queries, d)
+	for _, other := range others {
+		queries = append(queries, other)
+	}
+	return queries
+}
+
+// unparenthesizedUnionDialects dialects don't allow parenthesized queries in UNION, eg: (SELECT ... LIMIT 1)
+var unparenthesizedUnionDialects = map[string]bool{"sqlite": true}
+
+func union(operator string, queries []subQuery) Dao {
+	if len(queries) == 0 {
+		return &DO{}
+	}
+
+	first := queries[0].underlyingDO()
+	placeholders := make([]string, len(queries))
+	exprs := make([]interface{}, len(queries))
+	columns := -1
+	for i, query := range queries {
+		do := query.underlyingDO()
+		if err := do.db.Error; err != nil {
+			return first.withError(err)
+		}
+		if n := do.selectedColumns(); n >= 0 {
+			if columns >= 0 && n != columns {
+				return first.withError(fmt.Errorf("%s: queries select different number of columns: %d and %d", operator, columns, n))
+			}
+			columns = n
+		}
+
+		placeholders[i] = "?"
+		// ORDER BY and LIMIT of a single query must be enclosed in parentheses
+		if _, ok := do.db.Statement.Clauses["ORDER BY"]; ok {
+			placeholders[i] = "(?)"
+		} else if _, ok := do.db.Statement.Clauses["LIMIT"]; ok {
+			placeholders[i] = "(?)"
+		}
+		if placeholders[i] == "(?)" && unparenthesizedUnionDialects[do.db.Dialector.Name()] {
+			return first.withError(fmt.Errorf("%s: ORDER BY and LIMIT of a single query are not supported by %s", operator, do.db.Dialector.Name()))
+		}
+		exprs[i] = do.db
+	}
+
+	alias := first.TableName()
+	if alias == "" {
+		alias = "t"
+	}
+	table := clause.Expr{SQL: "(" + strings.Join(placeholders, " "+operator+" ") + ") AS " + first.Quote(alias), Vars: exprs}
+	return first.getInstance(fromDerivedTable(first.db.Session(&gorm.Session{NewDB: true}).Unscoped(), alias, table))
+}
+
+// derivedTable FROM clause of table expression, eg: (SELECT ...) AS `t`
+type derivedTable struct{ clause.Expr }
+
+func (derivedTable) Name() string { return "FROM" }
+
+func (t derivedTable) MergeClause(c *clause.Clause) { c.Expression = t }
+
+// fromDerivedTable return db selecting from table expression, which is referred as alias,
+// so that columns of current table are quoted with alias instead of the expression, db should be unscoped
+// as columns of model (eg: soft delete column) may be not selected by the expression
+func fromDerivedTable(db *gorm.DB, alias string, table clause.Expr) *gorm.DB {
+	db = db.Clauses(derivedTable{table})
+	db.Statement.Table = alias
+	return db.Session(new(gorm.Session))
+}
+
+// selectedColumns return number of columns selected by query, -1 if it is unknown, eg: SELECT * of joined tables
+func (d *DO) selectedColumns() int {
+	stmt := d.db.Statement
+	if c, ok := stmt.Clauses["SELECT"]; ok {
+		switch expr := c.Expression.(type) {
+		case clause.Expr:
+			return countColumns(strings.TrimPrefix(expr.SQL, "DISTINCT "))
+		case clause.Select:
+			if len(expr.Columns) > 0 {
+				return len(expr.Columns)
+			}
+		}
+	}
+	if len(stmt.Selects) > 0 {
+		columns := 0
+		for _, s := range stmt.Selects {
+			n := countColumns(s)
+			if n < 0 {
+				return -1
+			}
+			columns += n
+		}
+		return columns
+	}
+
+	// SELECT * of model table
+	if _, joined := stmt.Clauses["FROM"]; joined || d.schema == nil || (stmt.Table != "" && stmt.Table != d.schema.Table) {
+		return -1
+	}
+	return len(d.schema.DBNames)
+}
+
+// countColumns count columns of select list split by commas out of parentheses and quotes, -1 if * is selected
+func countColumns(list string) int {
+	var (
+		columns, depth int
+		quote          rune
+		column         strings.Builder
+	)
+	next := func() bool {
+		c := strings.TrimSpace(column.String())
+		column.Reset()
+		if c == "*" || strings.HasSuffix(c, ".*") {
+			return false
+		}
+		columns++
+		return true
+	}
+	for _, r := range list {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '`' || r == '"' || r == '\'':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			if !next() {
+				return -1
+			}
+			continue
+		}
+		column.WriteRune(r)
+	}
+	if strings.TrimSpace(column.String()) == "" {
+		return columns
+	}
+	if !next() {
+		return -1
+	}
+	return columns
+}
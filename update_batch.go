@@ -0,0 +1,236 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/field"
+)
+
+// UpdateBatch update columns of records to their own values in one statement per batch, eg:
+//
+//	UPDATE users SET name = CASE id WHEN 1 THEN 'a' WHEN 2 THEN 'b' ELSE name END WHERE id IN (1,2)
+//
+// records are matched by primary keys (composite primary keys are supported), updatable columns except primary keys,
+// create time, soft delete and tenant columns are updated if no column is specified, update time is set to now,
+// records of sharded model are updated in shards of their sharding keys, batches are updated in a transaction
+// if there are several, version column is increased but not checked
+func (d *DO) UpdateBatch(values interface{}, batchSize int, columns ...field.Expr) (info resultInfo, err error) {
+	if ok, err := d.intercept("UpdateBatch",
+		func(d *DO) (err error) { info, err = d.UpdateBatch(values, batchSize, columns...); return err },
+		func(d *DO) error { _, err := d.UpdateBatch(values, batchSize, columns...); return err },
+	); ok {
+		return info, err
+	}
+	defer d.invalidateCache()
+
+	if d.schema == nil || len(d.schema.PrimaryFields) == 0 {
+		return resultInfo{Error: gorm.ErrPrimaryKeyRequired}, gorm.ErrPrimaryKeyRequired
+	}
+	fields, err := d.updateBatchFields(columns)
+	if err != nil {
+		return resultInfo{Error: err}, err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return resultInfo{Error: gorm.ErrInvalidValue}, gorm.ErrInvalidValue
+	}
+	if rv.Len() == 0 {
+		return
+	}
+	if err := d.assignTenant(values); err != nil {
+		return resultInfo{Error: err}, err
+	}
+	if batchSize <= 0 {
+		batchSize = rv.Len()
+	}
+
+	update := func(tx *gorm.DB) error {
+		return d.shardUpdate(tx, rv, func(tx *gorm.DB, records reflect.Value) error {
+			for i := 0; i < records.Len(); i += batchSize {
+				end := i + batchSize
+				if end > records.Len() {
+					end = records.Len()
+				}
+				rowsAffected, err := d.updateBatch(tx, records.Slice(i, end), fields)
+				if err != nil {
+					return err
+				}
+				info.RowsAffected += rowsAffected
+			}
+			return nil
+		})
+	}
+	if rv.Len() > batchSize || (d.sharding != nil && rv.Len() > 1) {
+		err = transaction(d.db, update)
+	} else {
+		err = update(d.db)
+	}
+	info.Error = err
+	return info, err
+}
+
+// shardUpdate group records by shards of their sharding keys and update each group in its shard
+func (d *DO) shardUpdate(db *gorm.DB, records reflect.Value, update func(tx *gorm.DB, records reflect.Value) error) error {
+	s := d.sharding
+	if s == nil {
+		return update(db, records)
+	}
+
+	var (
+		suffixes []string
+		groups   = make(map[string]reflect.Value)
+	)
+	for i := 0; i < records.Len(); i++ {
+		key, err := s.keyOf(reflect.Indirect(records.Index(i)))
+		if err != nil {
+			return err
+		}
+		suffix, err := s.Suffix(key)
+		if err != nil {
+			return err
+		}
+		if _, ok := groups[suffix]; !ok {
+			suffixes = append(suffixes, suffix)
+			groups[suffix] = reflect.MakeSlice(reflect.SliceOf(records.Type().Elem()), 0, records.Len())
+		}
+		groups[suffix] = reflect.Append(groups[suffix], records.Index(i))
+	}
+	for _, suffix := range suffixes {
+		if err := update(s.inShard(db, suffix), groups[suffix]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateBatchFields return fields to be updated, fields maintained by gen or GORM are skipped by default:
+// primary keys, version, create time, update time(set to now by GORM), soft delete and tenant columns
+func (d *DO) updateBatchFields(columns []field.Expr) ([]*schema.Field, error) {
+	var fields []*schema.Field
+	if len(columns) == 0 {
+		for _, f := range d.schema.Fields {
+			if f.DBName != "" && f.Updatable && !f.PrimaryKey && !d.isMaintainedField(f) {
+				fields = append(fields, f)
+			}
+		}
+		return fields, nil
+	}
+
+	for _, column := range columns {
+		name := column.ColumnName().String()
+		f := d.schema.LookUpField(name)
+		if f == nil || f.DBName == "" {
+			return nil, fmt.Errorf("UpdateBatch: column %s is not field of model", name)
+		}
+		if f.PrimaryKey {
+			return nil, fmt.Errorf("UpdateBatch: primary key %s can not be updated", name)
+		}
+		if d.tenant != nil && f.DBName == d.tenant.column {
+			return nil, fmt.Errorf("UpdateBatch: tenant column %s can not be updated", name)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// isMaintainedField whether value of field is maintained by gen or GORM instead of records
+func (d *DO) isMaintainedField(f *schema.Field) bool {
+	switch {
+	case f.AutoCreateTime > 0, f.AutoUpdateTime > 0:
+		return true
+	case d.version != nil && f == d.version.field:
+		return true
+	case d.softDelete != nil && f.DBName == d.softDelete.column:
+		return true
+	case d.tenant != nil && f.DBName == d.tenant.column:
+		return true
+	}
+	return false
+}
+
+// updateBatch update records in one statement
+func (d *DO) updateBatch(tx *gorm.DB, records reflect.Value, fields []*schema.Field) (rowsAffected int64, err error) {
+	primaryFields := d.schema.PrimaryFields
+
+	// conditions match each record by primary keys
+	matches := make([][]clause.Expression, records.Len())
+	for i := 0; i < records.Len(); i++ {
+		record := reflect.Indirect(records.Index(i))
+		for _, pf := range primaryFields {
+			value, isZero := pf.ValueOf(record)
+			if isZero {
+				return 0, gorm.ErrPrimaryKeyRequired
+			}
+			matches[i] = append(matches[i], clause.Eq{Column: clause.Column{Name: pf.DBName}, Value: value})
+		}
+	}
+
+	set := make(map[string]interface{}, len(fields)+1)
+	for _, f := range fields {
+		set[f.DBName] = caseWhen(records, primaryFields, matches, f)
+	}
+	if d.version != nil {
+		set[d.version.column()] = d.version.increase()
+	}
+
+	var where clause.Expression
+	if len(primaryFields) == 1 {
+		keys := make([]interface{}, len(matches))
+		for i, match := range matches {
+			keys[i] = match[0].(clause.Eq).Value
+		}
+		where = clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: primaryFields[0].DBName}, Values: keys}
+	} else {
+		ors := make([]clause.Expression, len(matches))
+		for i, match := range matches {
+			ors[i] = clause.And(match...)
+		}
+		// wrapped with AND, OR conditions at top level of WHERE are joined with OR by GORM
+		where = clause.AndConditions{Exprs: []clause.Expression{clause.Or(ors...)}}
+	}
+	result := tx.Model(d.model).Where(where).Updates(set)
+	return result.RowsAffected, result.Error
+}
+
+// caseWhen CASE expression choose value of field by primary keys of records, ELSE keeps the original value
+// and determines the type of the expression in databases like Postgres
+func caseWhen(records reflect.Value, primaryFields []*schema.Field, matches [][]clause.Expression, f *schema.Field) clause.Expr {
+	var (
+		sql  strings.Builder
+		vars = make([]interface{}, 0, 2*records.Len()+2)
+	)
+	sql.WriteString("CASE")
+	if len(primaryFields) == 1 {
+		sql.WriteString(" ?")
+		vars = append(vars, clause.Column{Name: primaryFields[0].DBName})
+	}
+	for i := 0; i < records.Len(); i++ {
+		value, _ := f.ValueOf(reflect.Indirect(records.Index(i)))
+		if len(primaryFields) == 1 {
+			sql.WriteString(" WHEN ? THEN ?")
+			vars = append(vars, matches[i][0].(clause.Eq).Value, value)
+			continue
+		}
+
+		sql.WriteString(" WHEN")
+		for j, match := range matches[i] {
+			if j > 0 {
+				sql.WriteString(" AND")
+			}
+			sql.WriteString(" ? = ?")
+			vars = append(vars, match.(clause.Eq).Column, match.(clause.Eq).Value)
+		}
+		sql.WriteString(" THEN ?")
+		vars = append(vars, value)
+	}
+	sql.WriteString(" ELSE ? END")
+	vars = append(vars, clause.Column{Name: f.DBName})
+	return clause.Expr{SQL: sql.String(), Vars: vars}
+}
@@ -0,0 +1,100 @@
+package gen
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gorm.io/gen/field"
+)
+
+// conflictPendingKey db setting key marks OnConflict which is not followed by DoUpdate or DoNothing yet
+const conflictPendingKey = "gen:conflict_pending"
+
+// OnConflict specify conflict target columns of upsert, must be followed by DoUpdate or DoNothing,
+// conflict target is ignored by MySQL which uses unique keys of the table
+func (d *DO) OnConflict(columns ...field.Expr) Dao {
+	conflictColumns := make([]clause.Column, len(columns))
+	for i, column := range columns {
+		conflictColumns[i] = clause.Column{Name: column.ColumnName().String()}
+	}
+	d = d.getInstance(d.db.Set(conflictPendingKey, true).Scopes(checkConflictPending).Session(new(gorm.Session)))
+	d.conflictColumns = conflictColumns
+	return d
+}
+
+// checkConflictPending reject statement of OnConflict without DoUpdate or DoNothing, which is dropped silently otherwise
+func checkConflictPending(db *gorm.DB) *gorm.DB {
+	if pending, _ := db.Get(conflictPendingKey); pending == true {
+		_ = db.AddError(fmt.Errorf("OnConflict: must be followed by DoUpdate or DoNothing"))
+	}
+	return db
+}
+
+// DoUpdate update columns of the conflicted row,
+// column without value is updated with the value to be inserted, eg: u.Name,
+// column with value is updated with it, eg: u.Name.Value("modi"), u.Counter.Add(1)
+func (d *DO) DoUpdate(columns ...field.AssignExpr) Dao {
+	if len(columns) == 0 {
+		return d.withError(fmt.Errorf("DoUpdate: no column to update"))
+	}
+	// conflict target is required by ON CONFLICT DO UPDATE, except dialects building the clause themselves,
+	// eg: ON DUPLICATE KEY UPDATE of MySQL
+	if _, ok := d.db.ClauseBuilders[clause.OnConflict{}.Name()]; !ok && len(d.conflictColumns) == 0 {
+		return d.withError(fmt.Errorf("DoUpdate: conflict target is required, specify it by OnConflict"))
+	}
+
+	set := make(clause.Set, 0, len(columns))
+	for _, column := range columns {
+		name := column.BuildColumn(d.db.Statement, field.WithoutQuote).String()
+		assignment := clause.Assignment{Column: clause.Column{Name: name}}
+		switch e := column.AssignExpr().(type) {
+		case clause.NamedExpr:
+			assignment.Value = clause.Column{Table: "excluded", Name: name}
+		case clause.Eq:
+			assignment.Value = e.Value
+		case clause.Expr:
+			assignment.Value = d.unqualify(e)
+		default:
+			return d.withError(fmt.Errorf("DoUpdate: unsupported expression of column %s", name))
+		}
+		set = append(set, assignment)
+	}
+	return d.onConflict(clause.OnConflict{DoUpdates: set})
+}
+
+// DoNothing ignore the conflicted row
+func (d *DO) DoNothing() Dao {
+	return d.onConflict(clause.OnConflict{DoNothing: true})
+}
+
+func (d *DO) onConflict(onConflict clause.OnConflict) Dao {
+	onConflict.Columns = d.conflictColumns
+	d = d.getInstance(d.db.Set(conflictPendingKey, false).Clauses(onConflict))
+	d.conflictColumns = nil
+	return d
+}
+
+// qualifiedConflictDialects dialects require columns of the conflicted row qualified with the target table,
+// eg: PostgreSQL rejects unqualified column as ambiguous with column of EXCLUDED
+var qualifiedConflictDialects = map[string]bool{"postgres": true}
+
+// unqualify remove table of model's columns in expression, conflicted row is referenced without table name,
+// or qualified with current table in dialects requiring it, which also works when the table is aliased or sharded
+func (d *DO) unqualify(e clause.Expr) clause.Expr {
+	table := ""
+	if qualifiedConflictDialects[d.db.Dialector.Name()] {
+		table = clause.CurrentTable
+	}
+	vars := make([]interface{}, len(e.Vars))
+	for i, v := range e.Vars {
+		if column, ok := v.(clause.Column); ok && (column.Table == d.TableName() || column.Table == clause.CurrentTable) {
+			column.Table = table
+			v = column
+		}
+		vars[i] = v
+	}
+	e.Vars = vars
+	return e
+}